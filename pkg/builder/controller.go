@@ -17,13 +17,17 @@ limitations under the License.
 package builder
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -67,6 +71,9 @@ func (blder *Builder) ForType(apiType runtime.Object) *Builder {
 type ForInput struct {
 	object     runtime.Object
 	predicates []predicate.Predicate
+	debounce   time.Duration
+	priorities *handler.PriorityFuncs
+	resync     time.Duration
 }
 
 // For defines the type of Object being *reconciled*, and configures the ControllerManagedBy to respond to create / delete /
@@ -87,6 +94,8 @@ func (blder *Builder) For(object runtime.Object, opts ...ForOption) *Builder {
 type OwnsInput struct {
 	object     runtime.Object
 	predicates []predicate.Predicate
+	debounce   time.Duration
+	priorities *handler.PriorityFuncs
 }
 
 // Owns defines types of Objects being *generated* by the ControllerManagedBy, and configures the ControllerManagedBy to respond to
@@ -107,11 +116,15 @@ type WatchesInput struct {
 	src          source.Source
 	eventhandler handler.EventHandler
 	predicates   []predicate.Predicate
+	index        *Index
+	debounce     time.Duration
+	priorities   *handler.PriorityFuncs
 }
 
 // Watches exposes the lower-level ControllerManagedBy Watches functions through the builder.  Consider using
 // Owns or For instead of Watches directly.
 // Specified predicates are registered only for given source.
+// Use ByIndex to reconcile the objects of the For type that refer to the watched objects.
 func (blder *Builder) Watches(src source.Source, eventhandler handler.EventHandler, opts ...WatchesOption) *Builder {
 	input := WatchesInput{src: src, eventhandler: eventhandler}
 	for _, opt := range opts {
@@ -189,20 +202,29 @@ func (blder *Builder) Build(r reconcile.Reconciler) (controller.Controller, erro
 func (blder *Builder) doWatch() error {
 	// Reconcile type
 	src := &source.Kind{Type: blder.forInput.object}
-	hdler := &handler.EnqueueRequestForObject{}
+	hdler := blder.forHandler()
 	allPredicates := append(blder.globalPredicates, blder.forInput.predicates...)
 	err := blder.ctrl.Watch(src, hdler, allPredicates...)
 	if err != nil {
 		return err
 	}
 
+	// Periodically reconcile all the objects of the type, with the GenericFunc priority
+	if blder.forInput.resync > 0 {
+		src := &source.Periodic{Type: blder.forInput.object, Interval: blder.forInput.resync, JitterFactor: 0.1}
+		if err := blder.ctrl.Watch(src, hdler, allPredicates...); err != nil {
+			return err
+		}
+	}
+
 	// Watches the managed types
 	for _, own := range blder.ownsInput {
 		src := &source.Kind{Type: own.object}
-		hdler := &handler.EnqueueRequestForOwner{
+		var hdler handler.EventHandler = &handler.EnqueueRequestForOwner{
 			OwnerType:    blder.forInput.object,
 			IsController: true,
 		}
+		hdler = blder.debounced(prioritized(hdler, own.priorities), own.debounce)
 		allPredicates := append([]predicate.Predicate(nil), blder.globalPredicates...)
 		allPredicates = append(allPredicates, own.predicates...)
 		if err := blder.ctrl.Watch(src, hdler, allPredicates...); err != nil {
@@ -212,9 +234,17 @@ func (blder *Builder) doWatch() error {
 
 	// Do the watch requests
 	for _, w := range blder.watchesInput {
+		hdler := w.eventhandler
+		if w.index != nil {
+			var err error
+			if hdler, err = blder.doIndex(w.index); err != nil {
+				return err
+			}
+		}
+		hdler = blder.debounced(prioritized(hdler, w.priorities), w.debounce)
 		allPredicates := append([]predicate.Predicate(nil), blder.globalPredicates...)
 		allPredicates = append(allPredicates, w.predicates...)
-		if err := blder.ctrl.Watch(w.src, w.eventhandler, allPredicates...); err != nil {
+		if err := blder.ctrl.Watch(w.src, hdler, allPredicates...); err != nil {
 			return err
 		}
 
@@ -222,6 +252,59 @@ func (blder *Builder) doWatch() error {
 	return nil
 }
 
+// forHandler returns the handler of the For type, which enqueues the requests for the
+// GenericEvents of the periodic resyncs with a LowPriority by default.
+func (blder *Builder) forHandler() handler.EventHandler {
+	priorities := handler.PriorityFuncs{}
+	if blder.forInput.priorities != nil {
+		priorities = *blder.forInput.priorities
+	}
+	if priorities.GenericFunc == nil {
+		priorities.GenericFunc = func(event.GenericEvent) int { return priorityqueue.LowPriority }
+	}
+	var hdler handler.EventHandler = &handler.EnqueueRequestForObject{}
+	hdler = prioritized(hdler, &priorities)
+	return blder.debounced(hdler, blder.forInput.debounce)
+}
+
+// prioritized wraps the given handler to set the priority of its requests, if set.
+func prioritized(hdler handler.EventHandler, priorities *handler.PriorityFuncs) handler.EventHandler {
+	if priorities == nil || hdler == nil {
+		return hdler
+	}
+	return handler.WithPriority(hdler, *priorities)
+}
+
+// debounced wraps the given handler to debounce its requests over window, if set.
+func (blder *Builder) debounced(hdler handler.EventHandler, window time.Duration) handler.EventHandler {
+	if window <= 0 || hdler == nil {
+		return hdler
+	}
+	// the controller has been built, so its name is known
+	name, _ := blder.getControllerName()
+	return newDebouncedHandler(name, hdler, window)
+}
+
+// doIndex registers the given index on the For type, unless it is already registered
+// on the manager's FieldIndexer, and returns an event handler enqueuing the objects
+// found through it.
+func (blder *Builder) doIndex(index *Index) (handler.EventHandler, error) {
+	if index.extractValue == nil {
+		return nil, fmt.Errorf("must provide a function extracting the values of index %q", index.name)
+	}
+	err := blder.mgr.GetFieldIndexer().IndexField(context.Background(), blder.forInput.object, index.name, index.extractValue)
+	if err != nil && !isIndexConflict(err) {
+		return nil, fmt.Errorf("unable to register index %q: %w", index.name, err)
+	}
+	return handler.EnqueueRequestsFromIndex(blder.forInput.object, index.name), nil
+}
+
+// isIndexConflict returns whether err is the error of the informers when an index
+// with the same name is already registered.
+func isIndexConflict(err error) bool {
+	return strings.HasPrefix(err.Error(), "indexer conflict")
+}
+
 func (blder *Builder) loadRestConfig() {
 	if blder.config == nil {
 		blder.config = blder.mgr.GetConfig()
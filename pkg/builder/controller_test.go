@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -32,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -204,6 +206,162 @@ var _ = Describe("application", func() {
 			doReconcileTest("4", stop, bldr, m, true)
 			close(done)
 		}, 10)
+
+		It("should Reconcile the objects found through the index of a Watches request", func(done Done) {
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			bldr := ControllerManagedBy(m).
+				For(&appsv1.Deployment{}).
+				Watches(&source.Kind{Type: &appsv1.ReplicaSet{}}, nil,
+					ByIndex("replicaSetName", func(obj runtime.Object) []string {
+						// refer to the ReplicaSet doReconcileTest creates for the Deployment
+						return []string{strings.Replace(obj.(*appsv1.Deployment).Name, "deploy-name-", "rs-name-", 1)}
+					}))
+			doReconcileTest("6", stop, bldr, m, true)
+			close(done)
+		}, 10)
+
+		It("should share an index used by several controllers", func() {
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			byReplicaSet := ByIndex("sharedReplicaSetName", func(obj runtime.Object) []string {
+				return []string{obj.(*appsv1.Deployment).Name}
+			})
+			for _, name := range []string{"shared_index_1", "shared_index_2"} {
+				err = ControllerManagedBy(m).
+					Named(name).
+					For(&appsv1.Deployment{}).
+					Watches(&source.Kind{Type: &appsv1.ReplicaSet{}}, nil, byReplicaSet).
+					Complete(noop)
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		It("should use an index registered beforehand on the manager", func() {
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			extractValue := func(obj runtime.Object) []string {
+				return []string{obj.(*appsv1.Deployment).Name}
+			}
+			Expect(m.GetFieldIndexer().IndexField(context.TODO(), &appsv1.Deployment{}, "userReplicaSetName", extractValue)).To(Succeed())
+			err = ControllerManagedBy(m).
+				Named("user_index").
+				For(&appsv1.Deployment{}).
+				Watches(&source.Kind{Type: &appsv1.ReplicaSet{}}, nil, ByIndex("userReplicaSetName", extractValue)).
+				Complete(noop)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should Reconcile For and Owns objects with debounced watches", func(done Done) {
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			bldr := ControllerManagedBy(m).
+				For(&appsv1.Deployment{}, WithDebounce(10*time.Millisecond)).
+				Owns(&appsv1.ReplicaSet{}, WithDebounce(10*time.Millisecond))
+			doReconcileTest("7", stop, bldr, m, true)
+			close(done)
+		}, 10)
+
+		It("should Reconcile For and Owns objects with prioritized watches", func(done Done) {
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			urgent := WithPriority(handler.PriorityFuncs{
+				CreateFunc: func(event.CreateEvent) int { return priorityqueue.HighPriority },
+			})
+			bldr := ControllerManagedBy(m).
+				For(&appsv1.Deployment{}, urgent, WithDebounce(10*time.Millisecond)).
+				Owns(&appsv1.ReplicaSet{}, urgent).
+				WithOptions(controller.Options{UsePriorityQueue: true})
+			doReconcileTest("8", stop, bldr, m, true)
+			close(done)
+		}, 10)
+
+		It("should periodically Reconcile the For objects", func(done Done) {
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			reconciles := make(chan reconcile.Request, 10)
+			err = ControllerManagedBy(m).
+				For(&corev1.ConfigMap{}, WithPeriodicResync(50*time.Millisecond)).
+				Complete(reconcile.Func(func(req reconcile.Request) (reconcile.Result, error) {
+					if req.Name == "periodic-resync" {
+						select {
+						case reconciles <- req:
+						default:
+						}
+					}
+					return reconcile.Result{}, nil
+				}))
+			Expect(err).NotTo(HaveOccurred())
+
+			go func() {
+				defer GinkgoRecover()
+				Expect(m.Start(stop)).NotTo(HaveOccurred())
+			}()
+
+			By("Creating a ConfigMap")
+			cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "periodic-resync"}}
+			Expect(m.GetClient().Create(context.TODO(), cm)).To(Succeed())
+
+			By("Waiting for the ConfigMap to be reconciled again without changes")
+			expected := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "periodic-resync"}}
+			for i := 0; i < 3; i++ {
+				Expect(<-reconciles).To(Equal(expected))
+			}
+			close(done)
+		}, 10)
+	})
+
+	Describe("For handler", func() {
+		var q priorityqueue.PriorityQueue
+
+		BeforeEach(func() {
+			q = priorityqueue.New(workqueue.DefaultControllerRateLimiter())
+		})
+
+		AfterEach(func() {
+			q.ShutDown()
+		})
+
+		request := func(name string) reconcile.Request {
+			return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: name}}
+		}
+
+		It("should enqueue the periodic resyncs with a low priority", func() {
+			blder := (&Builder{}).For(&corev1.ConfigMap{}, WithPeriodicResync(time.Minute))
+			hdler := blder.forHandler()
+
+			resynced := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "resynced"}}
+			created := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "created"}}
+			hdler.Generic(event.GenericEvent{Meta: resynced, Object: resynced}, q)
+			hdler.Create(event.CreateEvent{Meta: created, Object: created}, q)
+
+			i1, _ := q.Get()
+			i2, _ := q.Get()
+			Expect([]interface{}{i1, i2}).To(Equal([]interface{}{request("created"), request("resynced")}))
+		})
+
+		It("should enqueue the requests with the priorities given with WithPriority", func() {
+			blder := (&Builder{}).For(&corev1.ConfigMap{}, WithPriority(handler.PriorityFuncs{
+				CreateFunc:  func(event.CreateEvent) int { return priorityqueue.LowPriority },
+				GenericFunc: func(event.GenericEvent) int { return priorityqueue.HighPriority },
+			}))
+			hdler := blder.forHandler()
+
+			created := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "created"}}
+			resynced := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "resynced"}}
+			hdler.Create(event.CreateEvent{Meta: created, Object: created}, q)
+			hdler.Generic(event.GenericEvent{Meta: resynced, Object: resynced}, q)
+
+			i1, _ := q.Get()
+			i2, _ := q.Get()
+			Expect([]interface{}{i1, i2}).To(Equal([]interface{}{request("resynced"), request("created")}))
+		})
 	})
 
 	Describe("Set custom predicates", func() {
@@ -0,0 +1,197 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/internal/controller/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/internal/handlerutil"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+var _ handler.EventHandler = &debouncedHandler{}
+var _ inject.Stoppable = &debouncedHandler{}
+
+// debouncedHandler delays the requests enqueued by an event handler by a window,
+// merging the requests enqueued again for the same object during the window.
+type debouncedHandler struct {
+	*handlerutil.QueueWrapper
+
+	window   time.Duration
+	received prometheus.Counter
+	enqueued prometheus.Counter
+
+	// mu guards queues
+	mu sync.Mutex
+
+	// queues holds the debouncing wrapper of each queue events were handled for
+	queues map[workqueue.RateLimitingInterface]*debouncingQueue
+}
+
+func newDebouncedHandler(controllerName string, h handler.EventHandler, window time.Duration) *debouncedHandler {
+	d := &debouncedHandler{
+		window:   window,
+		received: ctrlmetrics.DebouncedEventsReceived.WithLabelValues(controllerName),
+		enqueued: ctrlmetrics.DebouncedRequestsEnqueued.WithLabelValues(controllerName),
+		queues:   map[workqueue.RateLimitingInterface]*debouncingQueue{},
+	}
+	d.QueueWrapper = &handlerutil.QueueWrapper{Handler: h, Queue: d.queueFor}
+	return d
+}
+
+// InjectStopChannel is internal should be called only by the Controller.
+// It is used to stop the pending timers once the controller stops.
+func (h *debouncedHandler) InjectStopChannel(stop <-chan struct{}) error {
+	go func() {
+		<-stop
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		for _, dq := range h.queues {
+			dq.stop()
+		}
+	}()
+	return nil
+}
+
+func (h *debouncedHandler) queueFor(_ interface{}, q workqueue.RateLimitingInterface) workqueue.RateLimitingInterface {
+	h.received.Inc()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	dq, ok := h.queues[q]
+	if !ok {
+		dq = &debouncingQueue{
+			RateLimitingInterface: q,
+			handler:               h,
+			pending:               map[interface{}]*pendingItem{},
+		}
+		h.queues[q] = dq
+	}
+	return dq
+}
+
+// debouncingQueue adds items to the underlying queue once the window of its
+// handler elapsed after they were first added.  It is a PriorityQueue, so that
+// the handlers it wraps can set the priority of their requests: an item is added
+// with the highest priority it was added with during the window, if the
+// underlying queue is a PriorityQueue too.
+type debouncingQueue struct {
+	workqueue.RateLimitingInterface
+	handler *debouncedHandler
+
+	// mu guards pending and stopped
+	mu sync.Mutex
+
+	// pending are the items waiting to be added to the queue
+	pending map[interface{}]*pendingItem
+
+	// stopped is set once the controller stopped
+	stopped bool
+}
+
+var _ priorityqueue.PriorityQueue = &debouncingQueue{}
+
+// pendingItem is an item waiting to be added to the queue.
+type pendingItem struct {
+	timer    *time.Timer
+	priority int
+}
+
+// Add delays adding item to the queue, unless it is already waiting to be added.
+func (q *debouncingQueue) Add(item interface{}) {
+	q.AddWithPriority(item, priorityqueue.DefaultPriority)
+}
+
+// AddWithPriority delays adding item to the queue, unless it is already waiting
+// to be added, in which case its priority is raised to priority if higher.
+func (q *debouncingQueue) AddWithPriority(item interface{}, priority int) {
+	q.schedule(item, q.handler.window, priority, q.addWithPriority)
+}
+
+// AddAfter delays adding item to the queue by the longest of duration and the
+// window, unless it is already waiting to be added.
+func (q *debouncingQueue) AddAfter(item interface{}, duration time.Duration) {
+	delay := q.handler.window
+	if duration > delay {
+		delay = duration
+	}
+	q.schedule(item, delay, priorityqueue.DefaultPriority, q.addWithPriority)
+}
+
+// AddRateLimited delays adding item to the queue with AddRateLimited, unless it is
+// already waiting to be added.
+func (q *debouncingQueue) AddRateLimited(item interface{}) {
+	q.schedule(item, q.handler.window, priorityqueue.DefaultPriority, func(item interface{}, _ int) {
+		q.RateLimitingInterface.AddRateLimited(item)
+	})
+}
+
+// addWithPriority adds item to the underlying queue, with priority if it is a PriorityQueue.
+func (q *debouncingQueue) addWithPriority(item interface{}, priority int) {
+	if pq, ok := q.RateLimitingInterface.(priorityqueue.PriorityQueue); ok {
+		pq.AddWithPriority(item, priority)
+		return
+	}
+	q.RateLimitingInterface.Add(item)
+}
+
+// schedule calls add with item and its highest priority after delay, unless item is
+// already waiting to be added.
+func (q *debouncingQueue) schedule(item interface{}, delay time.Duration, priority int, add func(interface{}, int)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.stopped {
+		return
+	}
+	if p, ok := q.pending[item]; ok {
+		if priority > p.priority {
+			p.priority = priority
+		}
+		return
+	}
+	p := &pendingItem{priority: priority}
+	p.timer = time.AfterFunc(delay, func() {
+		q.mu.Lock()
+		delete(q.pending, item)
+		priority := p.priority
+		q.mu.Unlock()
+
+		if q.ShuttingDown() {
+			return
+		}
+		add(item, priority)
+		q.handler.enqueued.Inc()
+	})
+	q.pending[item] = p
+}
+
+// stop cancels the pending items, and drops the items added later.
+func (q *debouncingQueue) stop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.stopped = true
+	for item, p := range q.pending {
+		p.timer.Stop()
+		delete(q.pending, item)
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/internal/controller/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+var _ = Describe("debouncedHandler", func() {
+	var q workqueue.RateLimitingInterface
+
+	BeforeEach(func() {
+		q = controllertest.Queue{Interface: workqueue.New()}
+	})
+
+	pod := func(name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: name}}
+	}
+
+	It("should merge the requests enqueued for the same object during the window", func() {
+		h := newDebouncedHandler("debounce-merge", &handler.EnqueueRequestForObject{}, 100*time.Millisecond)
+
+		foo, bar := pod("foo"), pod("bar")
+		h.Create(event.CreateEvent{Meta: foo, Object: foo}, q)
+		h.Update(event.UpdateEvent{MetaOld: foo, ObjectOld: foo, MetaNew: foo, ObjectNew: foo}, q)
+		h.Update(event.UpdateEvent{MetaOld: foo, ObjectOld: foo, MetaNew: foo, ObjectNew: foo}, q)
+		h.Create(event.CreateEvent{Meta: bar, Object: bar}, q)
+
+		By("checking that nothing is enqueued before the window elapsed")
+		Expect(q.Len()).To(Equal(0))
+
+		By("checking that one request per object is enqueued after the window")
+		Eventually(q.Len).Should(Equal(2))
+		Consistently(q.Len, 200*time.Millisecond).Should(Equal(2))
+		i1, _ := q.Get()
+		i2, _ := q.Get()
+		Expect([]interface{}{i1, i2}).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "biz", Name: "foo"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "biz", Name: "bar"}},
+		))
+
+		By("checking the metrics")
+		Expect(testutil.ToFloat64(ctrlmetrics.DebouncedEventsReceived.WithLabelValues("debounce-merge"))).To(Equal(4.0))
+		Expect(testutil.ToFloat64(ctrlmetrics.DebouncedRequestsEnqueued.WithLabelValues("debounce-merge"))).To(Equal(2.0))
+	})
+
+	It("should enqueue the request again for events after the window", func() {
+		h := newDebouncedHandler("debounce-again", &handler.EnqueueRequestForObject{}, 10*time.Millisecond)
+
+		foo := pod("foo")
+		h.Create(event.CreateEvent{Meta: foo, Object: foo}, q)
+		Eventually(q.Len).Should(Equal(1))
+		i, _ := q.Get()
+		q.Done(i)
+
+		h.Delete(event.DeleteEvent{Meta: foo, Object: foo}, q)
+		Eventually(q.Len).Should(Equal(1))
+		Expect(testutil.ToFloat64(ctrlmetrics.DebouncedRequestsEnqueued.WithLabelValues("debounce-again"))).To(Equal(2.0))
+	})
+
+	It("should debounce the requests added after a delay or rate limited", func() {
+		h := newDebouncedHandler("debounce-delayed", handler.Funcs{
+			CreateFunc: func(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+				q.AddAfter(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "biz", Name: evt.Meta.GetName()}}, 50*time.Millisecond)
+			},
+			UpdateFunc: func(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+				q.AddRateLimited(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "biz", Name: evt.MetaNew.GetName()}})
+			},
+		}, 10*time.Millisecond)
+
+		foo := pod("foo")
+		h.Create(event.CreateEvent{Meta: foo, Object: foo}, q)
+		h.Update(event.UpdateEvent{MetaOld: foo, ObjectOld: foo, MetaNew: foo, ObjectNew: foo}, q)
+		Consistently(q.Len, 30*time.Millisecond).Should(Equal(0))
+		Eventually(q.Len).Should(Equal(1))
+		Consistently(q.Len, 50*time.Millisecond).Should(Equal(1))
+	})
+
+	It("should add the requests with the highest priority of their events", func() {
+		pq := priorityqueue.New(workqueue.DefaultControllerRateLimiter())
+		defer pq.ShutDown()
+		h := newDebouncedHandler("debounce-priority", handler.WithPriority(&handler.EnqueueRequestForObject{}, handler.PriorityFuncs{
+			CreateFunc: func(event.CreateEvent) int { return priorityqueue.LowPriority },
+			UpdateFunc: func(event.UpdateEvent) int { return priorityqueue.HighPriority },
+		}), 10*time.Millisecond)
+
+		foo, bar := pod("foo"), pod("bar")
+		h.Create(event.CreateEvent{Meta: foo, Object: foo}, pq)
+		h.Create(event.CreateEvent{Meta: bar, Object: bar}, pq)
+		h.Update(event.UpdateEvent{MetaOld: bar, ObjectOld: bar, MetaNew: bar, ObjectNew: bar}, pq)
+		h.Create(event.CreateEvent{Meta: bar, Object: bar}, pq)
+		Eventually(pq.Len).Should(Equal(2))
+
+		i1, _ := pq.Get()
+		i2, _ := pq.Get()
+		Expect([]interface{}{i1, i2}).To(Equal([]interface{}{
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "biz", Name: "bar"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "biz", Name: "foo"}},
+		}))
+	})
+
+	It("should drop the pending requests once stopped", func() {
+		h := newDebouncedHandler("debounce-stop", &handler.EnqueueRequestForObject{}, 20*time.Millisecond)
+		stop := make(chan struct{})
+		Expect(inject.StopChannelInto(stop, h)).To(BeTrue())
+
+		foo, bar := pod("foo"), pod("bar")
+		h.Create(event.CreateEvent{Meta: foo, Object: foo}, q)
+		close(stop)
+		Eventually(func() int {
+			h.Create(event.CreateEvent{Meta: bar, Object: bar}, q)
+			h.mu.Lock()
+			dq := h.queues[q]
+			h.mu.Unlock()
+			dq.mu.Lock()
+			defer dq.mu.Unlock()
+			return len(dq.pending)
+		}).Should(Equal(0))
+		Consistently(q.Len, 50*time.Millisecond).Should(Equal(0))
+	})
+
+})
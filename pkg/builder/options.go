@@ -17,6 +17,10 @@ limitations under the License.
 package builder
 
 import (
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
@@ -75,4 +79,118 @@ var _ ForOption = &Predicates{}
 var _ OwnsOption = &Predicates{}
 var _ WatchesOption = &Predicates{}
 
+// WithDebounce delays the requests enqueued for events by the given window, so that the
+// requests for the same object enqueued during the window are merged into one.  This
+// reduces the reconciles caused by objects changing frequently, at the cost of latency.
+// Requests added by event handlers with AddAfter or AddRateLimited are delayed by at
+// least the window as well.  Merged requests keep the highest priority given to them
+// with handler.WithPriority.
+func WithDebounce(window time.Duration) Debounce {
+	return Debounce{window: window}
+}
+
+// Debounce merges the requests enqueued for the same object over a window.
+type Debounce struct {
+	window time.Duration
+}
+
+// ApplyToFor applies this configuration to the given ForInput options.
+func (d Debounce) ApplyToFor(opts *ForInput) {
+	opts.debounce = d.window
+}
+
+// ApplyToOwns applies this configuration to the given OwnsInput options.
+func (d Debounce) ApplyToOwns(opts *OwnsInput) {
+	opts.debounce = d.window
+}
+
+// ApplyToWatches applies this configuration to the given WatchesInput options.
+func (d Debounce) ApplyToWatches(opts *WatchesInput) {
+	opts.debounce = d.window
+}
+
+var _ ForOption = &Debounce{}
+var _ OwnsOption = &Debounce{}
+var _ WatchesOption = &Debounce{}
+
+// WithPriority sets the priority of the requests enqueued for each type of event with
+// handler.WithPriority, for controllers using a priority queue (see
+// controller.Options.UsePriorityQueue).
+func WithPriority(priorities handler.PriorityFuncs) Priority {
+	return Priority{priorities: priorities}
+}
+
+// Priority sets the priority of the requests enqueued for events.
+type Priority struct {
+	priorities handler.PriorityFuncs
+}
+
+// ApplyToFor applies this configuration to the given ForInput options.
+func (p Priority) ApplyToFor(opts *ForInput) {
+	opts.priorities = &p.priorities
+}
+
+// ApplyToOwns applies this configuration to the given OwnsInput options.
+func (p Priority) ApplyToOwns(opts *OwnsInput) {
+	opts.priorities = &p.priorities
+}
+
+// ApplyToWatches applies this configuration to the given WatchesInput options.
+func (p Priority) ApplyToWatches(opts *WatchesInput) {
+	opts.priorities = &p.priorities
+}
+
+var _ ForOption = &Priority{}
+var _ OwnsOption = &Priority{}
+var _ WatchesOption = &Priority{}
+
+// WithPeriodicResync reconciles all the objects of the For type every interval, with
+// a jitter of up to 10%, in addition to the reconciles caused by their events.  This
+// allows a controller to correct drift from external systems at its own pace, without
+// resyncing the informers shared with other controllers.  The periodic requests get
+// the priorityqueue.LowPriority, unless WithPriority sets a GenericFunc.
+func WithPeriodicResync(interval time.Duration) PeriodicResync {
+	return PeriodicResync{interval: interval}
+}
+
+// PeriodicResync reconciles all the objects of the For type periodically.
+type PeriodicResync struct {
+	interval time.Duration
+}
+
+// ApplyToFor applies this configuration to the given ForInput options.
+func (p PeriodicResync) ApplyToFor(opts *ForInput) {
+	opts.resync = p.interval
+}
+
+var _ ForOption = &PeriodicResync{}
+
+// }}}
+
+// {{{ Watches Options
+
+// ByIndex makes a Watches request enqueue the objects of the For type which refer
+// to the watched objects, using handler.EnqueueRequestsFromIndex instead of the
+// event handler given to Watches, which may be nil.  The index is registered on
+// the For type with the manager's FieldIndexer when the controller is built, and
+// extractValue must return the names of the objects an object refers to.  An index
+// already registered on the FieldIndexer for the For type under the same name, by
+// another controller or with IndexField, is shared, and extractValue is ignored.
+func ByIndex(indexName string, extractValue client.IndexerFunc) Index {
+	return Index{name: indexName, extractValue: extractValue}
+}
+
+// Index looks up the objects to reconcile through a field index.
+type Index struct {
+	name         string
+	extractValue client.IndexerFunc
+}
+
+// ApplyToWatches applies this configuration to the given WatchesInput options.
+func (i Index) ApplyToWatches(opts *WatchesInput) {
+	opts.index = &i
+}
+
+var _ WatchesOption = &Index{}
+
 // }}}
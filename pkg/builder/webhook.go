@@ -17,6 +17,8 @@ limitations under the License.
 package builder
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
@@ -32,10 +34,21 @@ import (
 
 // WebhookBuilder builds a Webhook.
 type WebhookBuilder struct {
-	apiType runtime.Object
-	gvk     schema.GroupVersionKind
-	mgr     manager.Manager
-	config  *rest.Config
+	apiType         runtime.Object
+	customDefaulter admission.CustomDefaulter
+	customValidator admission.CustomValidator
+	defaulterPath   string
+	validatorPath   string
+	handlers        []pathHandler
+	gvk             schema.GroupVersionKind
+	mgr             manager.Manager
+	config          *rest.Config
+}
+
+// pathHandler is an admission.Handler served on a fixed path.
+type pathHandler struct {
+	path    string
+	handler admission.Handler
 }
 
 // WebhookManagedBy allows inform its manager.Manager
@@ -48,12 +61,53 @@ func WebhookManagedBy(m manager.Manager) *WebhookBuilder {
 // For takes a runtime.Object which should be a CR.
 // If the given object implements the admission.Defaulter interface, a MutatingWebhook will be wired for this type.
 // If the given object implements the admission.Validator interface, a ValidatingWebhook will be wired for this type.
+// See WithDefaulter and WithValidator to use separate implementations instead.
 func (blder *WebhookBuilder) For(apiType runtime.Object) *WebhookBuilder {
 	blder.apiType = apiType
 	return blder
 }
 
-// Complete builds the webhook.
+// WithDefaulter takes an admission.CustomDefaulter interface, a MutatingWebhook will be wired for this type,
+// instead of relying on the type implementing admission.Defaulter.
+func (blder *WebhookBuilder) WithDefaulter(defaulter admission.CustomDefaulter) *WebhookBuilder {
+	blder.customDefaulter = defaulter
+	return blder
+}
+
+// WithValidator takes an admission.CustomValidator interface, a ValidatingWebhook will be wired for this type,
+// instead of relying on the type implementing admission.Validator.
+func (blder *WebhookBuilder) WithValidator(validator admission.CustomValidator) *WebhookBuilder {
+	blder.customValidator = validator
+	return blder
+}
+
+// WithDefaulterPath sets the path the defaulting webhook is served on, instead of
+// the path generated from the GroupVersionKind of the type.  This allows serving
+// several defaulting webhooks for the same type.
+func (blder *WebhookBuilder) WithDefaulterPath(path string) *WebhookBuilder {
+	blder.defaulterPath = path
+	return blder
+}
+
+// WithValidatorPath sets the path the validating webhook is served on, instead of
+// the path generated from the GroupVersionKind of the type.  This allows serving
+// several validating webhooks for the same type.
+func (blder *WebhookBuilder) WithValidatorPath(path string) *WebhookBuilder {
+	blder.validatorPath = path
+	return blder
+}
+
+// WithHandler serves the given admission.Handler on the given path.  It may be
+// called several times to serve several handlers, and does not require For to be
+// called if no defaulting or validating webhook is wanted.
+func (blder *WebhookBuilder) WithHandler(path string, handler admission.Handler) *WebhookBuilder {
+	blder.handlers = append(blder.handlers, pathHandler{path: path, handler: handler})
+	return blder
+}
+
+// Complete builds the webhook.  It returns an error, without registering any
+// webhook, if one of the paths is already served by the webhook server or used
+// twice by this builder.
 func (blder *WebhookBuilder) Complete() error {
 	// Set the Config
 	blder.loadRestConfig()
@@ -69,6 +123,17 @@ func (blder *WebhookBuilder) loadRestConfig() {
 }
 
 func (blder *WebhookBuilder) registerWebhooks() error {
+	if blder.apiType == nil {
+		if len(blder.handlers) == 0 {
+			return errors.New("must provide an object with For() or a handler with WithHandler()")
+		}
+		if blder.customDefaulter != nil || blder.customValidator != nil ||
+			blder.defaulterPath != "" || blder.validatorPath != "" {
+			return errors.New("must provide an object with For() to use a defaulter or a validator")
+		}
+		return blder.registerHooks(blder.handlerHooks())
+	}
+
 	// Create webhook(s) for each type
 	var err error
 	blder.gvk, err = apiutil.GVKForObject(blder.apiType, blder.mgr.GetScheme())
@@ -76,8 +141,25 @@ func (blder *WebhookBuilder) registerWebhooks() error {
 		return err
 	}
 
-	blder.registerDefaultingWebhook()
-	blder.registerValidatingWebhook()
+	var hooks []pathHook
+	if mwh := blder.getDefaultingWebhook(); mwh != nil {
+		path := blder.defaulterPath
+		if path == "" {
+			path = generateMutatePath(blder.gvk)
+		}
+		hooks = append(hooks, pathHook{path: path, hook: mwh, kind: "mutating"})
+	}
+	if vwh := blder.getValidatingWebhook(); vwh != nil {
+		path := blder.validatorPath
+		if path == "" {
+			path = generateValidatePath(blder.gvk)
+		}
+		hooks = append(hooks, pathHook{path: path, hook: vwh, kind: "validating"})
+	}
+	hooks = append(hooks, blder.handlerHooks()...)
+	if err := blder.registerHooks(hooks); err != nil {
+		return err
+	}
 
 	err = blder.registerConversionWebhook()
 	if err != nil {
@@ -86,47 +168,66 @@ func (blder *WebhookBuilder) registerWebhooks() error {
 	return nil
 }
 
-// registerDefaultingWebhook registers a defaulting webhook if th
-func (blder *WebhookBuilder) registerDefaultingWebhook() {
+// pathHook is an admission webhook to be registered on a path.
+type pathHook struct {
+	path string
+	hook *admission.Webhook
+	kind string
+}
+
+func (blder *WebhookBuilder) handlerHooks() []pathHook {
+	hooks := make([]pathHook, 0, len(blder.handlers))
+	for _, h := range blder.handlers {
+		hooks = append(hooks, pathHook{path: h.path, hook: &admission.Webhook{Handler: h.handler}, kind: "custom"})
+	}
+	return hooks
+}
+
+// registerHooks registers the given webhooks after checking that none of their
+// paths are taken, so that either all or none of them are registered.
+func (blder *WebhookBuilder) registerHooks(hooks []pathHook) error {
+	seen := make(map[string]bool, len(hooks))
+	for _, h := range hooks {
+		if h.path == "" || !strings.HasPrefix(h.path, "/") {
+			return fmt.Errorf("invalid path %q for a %s webhook, it must start with \"/\"", h.path, h.kind)
+		}
+		if seen[h.path] || blder.isAlreadyHandled(h.path) {
+			return fmt.Errorf("can't register a %s webhook on %s, the path is already in use", h.kind, h.path)
+		}
+		seen[h.path] = true
+	}
+
+	for _, h := range hooks {
+		log.Info("Registering a "+h.kind+" webhook",
+			"GVK", blder.gvk,
+			"path", h.path)
+		blder.mgr.GetWebhookServer().Register(h.path, h.hook)
+	}
+	return nil
+}
+
+func (blder *WebhookBuilder) getDefaultingWebhook() *admission.Webhook {
+	if blder.customDefaulter != nil {
+		return admission.WithCustomDefaulter(blder.apiType, blder.customDefaulter)
+	}
 	defaulter, isDefaulter := blder.apiType.(admission.Defaulter)
 	if !isDefaulter {
 		log.Info("skip registering a mutating webhook, admission.Defaulter interface is not implemented", "GVK", blder.gvk)
-		return
-	}
-	mwh := admission.DefaultingWebhookFor(defaulter)
-	if mwh != nil {
-		path := generateMutatePath(blder.gvk)
-
-		// Checking if the path is already registered.
-		// If so, just skip it.
-		if !blder.isAlreadyHandled(path) {
-			log.Info("Registering a mutating webhook",
-				"GVK", blder.gvk,
-				"path", path)
-			blder.mgr.GetWebhookServer().Register(path, mwh)
-		}
+		return nil
 	}
+	return admission.DefaultingWebhookFor(defaulter)
 }
 
-func (blder *WebhookBuilder) registerValidatingWebhook() {
+func (blder *WebhookBuilder) getValidatingWebhook() *admission.Webhook {
+	if blder.customValidator != nil {
+		return admission.WithCustomValidator(blder.apiType, blder.customValidator)
+	}
 	validator, isValidator := blder.apiType.(admission.Validator)
 	if !isValidator {
 		log.Info("skip registering a validating webhook, admission.Validator interface is not implemented", "GVK", blder.gvk)
-		return
-	}
-	vwh := admission.ValidatingWebhookFor(validator)
-	if vwh != nil {
-		path := generateValidatePath(blder.gvk)
-
-		// Checking if the path is already registered.
-		// If so, just skip it.
-		if !blder.isAlreadyHandled(path) {
-			log.Info("Registering a validating webhook",
-				"GVK", blder.gvk,
-				"path", path)
-			blder.mgr.GetWebhookServer().Register(path, vwh)
-		}
+		return nil
 	}
+	return admission.ValidatingWebhookFor(validator)
 }
 
 func (blder *WebhookBuilder) registerConversionWebhook() error {
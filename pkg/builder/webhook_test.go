@@ -17,6 +17,7 @@ limitations under the License.
 package builder
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -28,6 +29,7 @@ import (
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/scheme"
@@ -267,6 +269,240 @@ var _ = Describe("application", func() {
 			Expect(w.Body).To(ContainSubstring(`"code":200`))
 		})
 
+		It("should scaffold webhooks with a custom defaulter and validator if given", func() {
+			By("creating a controller manager")
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("registering the type in the Scheme")
+			builder := scheme.Builder{GroupVersion: testValidatorGVK.GroupVersion()}
+			builder.Register(&TestValidator{}, &TestValidatorList{})
+			err = builder.AddToScheme(m.GetScheme())
+			Expect(err).NotTo(HaveOccurred())
+
+			err = WebhookManagedBy(m).
+				For(&TestValidator{}).
+				WithDefaulter(&TestCustomDefaulter{}).
+				WithValidator(&TestCustomValidator{}).
+				Complete()
+			Expect(err).NotTo(HaveOccurred())
+			svr := m.GetWebhookServer()
+			Expect(svr).NotTo(BeNil())
+
+			request := `{
+  "kind":"AdmissionReview",
+  "apiVersion":"admission.k8s.io/v1",
+  "request":{
+    "uid":"07e52e8d-4513-11e9-a716-42010a800270",
+    "kind":{
+      "group":"",
+      "version":"v1",
+      "kind":"TestValidator"
+    },
+    "resource":{
+      "group":"",
+      "version":"v1",
+      "resource":"testvalidator"
+    },
+    "namespace":"default",
+    "operation":"CREATE",
+    "object":{
+      "replica":5
+    },
+    "oldObject":null
+  }
+}`
+
+			stopCh := make(chan struct{})
+			close(stopCh)
+			// TODO: we may want to improve it to make it be able to inject dependencies,
+			// but not always try to load certs and return not found error.
+			err = svr.Start(stopCh)
+			if err != nil && !os.IsNotExist(err) {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			By("sending a request to the mutating webhook path")
+			path := generateMutatePath(testValidatorGVK)
+			req := httptest.NewRequest("POST", "http://svc-name.svc-ns.svc"+path, strings.NewReader(request))
+			req.Header.Add(http.CanonicalHeaderKey("Content-Type"), "application/json")
+			w := httptest.NewRecorder()
+			svr.WebhookMux.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			By("sanity checking the response contains reasonable fields")
+			Expect(w.Body).To(ContainSubstring(`"allowed":true`))
+			Expect(w.Body).To(ContainSubstring(`"patch":`))
+
+			By("sending a request to the validating webhook path")
+			path = generateValidatePath(testValidatorGVK)
+			req = httptest.NewRequest("POST", "http://svc-name.svc-ns.svc"+path, strings.NewReader(request))
+			req.Header.Add(http.CanonicalHeaderKey("Content-Type"), "application/json")
+			w = httptest.NewRecorder()
+			svr.WebhookMux.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			By("checking that the custom validator, rather than the type, validated the request")
+			Expect(w.Body).To(ContainSubstring(`"allowed":false`))
+			Expect(w.Body).To(ContainSubstring(`more than 3 replicas in default`))
+		})
+
+		It("should serve several webhooks for the same type on the given paths", func() {
+			By("creating a controller manager")
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("registering the type in the Scheme")
+			builder := scheme.Builder{GroupVersion: testValidatorGVK.GroupVersion()}
+			builder.Register(&TestValidator{}, &TestValidatorList{})
+			err = builder.AddToScheme(m.GetScheme())
+			Expect(err).NotTo(HaveOccurred())
+
+			err = WebhookManagedBy(m).
+				For(&TestValidator{}).
+				WithDefaulter(&TestCustomDefaulter{}).
+				WithDefaulterPath("/default-testvalidator").
+				WithValidatorPath("/validate-testvalidator-replicas").
+				Complete()
+			Expect(err).NotTo(HaveOccurred())
+
+			err = WebhookManagedBy(m).
+				For(&TestValidator{}).
+				WithValidator(&TestCustomValidator{}).
+				WithValidatorPath("/validate-testvalidator-namespace").
+				WithHandler("/audit-testvalidator", admission.HandlerFunc(func(ctx context.Context, req admission.Request) admission.Response {
+					return admission.Allowed("audited " + req.Namespace)
+				})).
+				Complete()
+			Expect(err).NotTo(HaveOccurred())
+			svr := m.GetWebhookServer()
+
+			request := `{
+  "kind":"AdmissionReview",
+  "apiVersion":"admission.k8s.io/v1",
+  "request":{
+    "uid":"07e52e8d-4513-11e9-a716-42010a800270",
+    "kind":{
+      "group":"",
+      "version":"v1",
+      "kind":"TestValidator"
+    },
+    "resource":{
+      "group":"",
+      "version":"v1",
+      "resource":"testvalidator"
+    },
+    "namespace":"default",
+    "operation":"CREATE",
+    "object":{
+      "replica":5
+    },
+    "oldObject":null
+  }
+}`
+			serve := func(path string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest("POST", "http://svc-name.svc-ns.svc"+path, strings.NewReader(request))
+				req.Header.Add(http.CanonicalHeaderKey("Content-Type"), "application/json")
+				w := httptest.NewRecorder()
+				svr.WebhookMux.ServeHTTP(w, req)
+				return w
+			}
+
+			stopCh := make(chan struct{})
+			close(stopCh)
+			err = svr.Start(stopCh)
+			if err != nil && !os.IsNotExist(err) {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			By("checking that the generated paths are not served")
+			Expect(serve(generateMutatePath(testValidatorGVK)).Code).To(Equal(http.StatusNotFound))
+			Expect(serve(generateValidatePath(testValidatorGVK)).Code).To(Equal(http.StatusNotFound))
+
+			By("sending a request to the mutating webhook path")
+			w := serve("/default-testvalidator")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body).To(ContainSubstring(`"allowed":true`))
+
+			By("sending a request to the validating webhook of the type")
+			w = serve("/validate-testvalidator-replicas")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body).To(ContainSubstring(`"allowed":true`))
+
+			By("sending a request to the custom validating webhook")
+			w = serve("/validate-testvalidator-namespace")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body).To(ContainSubstring(`"allowed":false`))
+			Expect(w.Body).To(ContainSubstring(`more than 3 replicas in default`))
+
+			By("sending a request to the handler")
+			w = serve("/audit-testvalidator")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body).To(ContainSubstring(`audited default`))
+		})
+
+		It("should return an error instead of registering webhooks on paths in use", func() {
+			By("creating a controller manager")
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("registering the type in the Scheme")
+			builder := scheme.Builder{GroupVersion: testValidatorGVK.GroupVersion()}
+			builder.Register(&TestValidator{}, &TestValidatorList{})
+			err = builder.AddToScheme(m.GetScheme())
+			Expect(err).NotTo(HaveOccurred())
+
+			err = WebhookManagedBy(m).
+				For(&TestValidator{}).
+				Complete()
+			Expect(err).NotTo(HaveOccurred())
+
+			By("registering the same type again")
+			err = WebhookManagedBy(m).
+				For(&TestValidator{}).
+				Complete()
+			Expect(err).To(MatchError(ContainSubstring("already in use")))
+
+			By("using the same path twice in a builder")
+			err = WebhookManagedBy(m).
+				For(&TestValidator{}).
+				WithDefaulter(&TestCustomDefaulter{}).
+				WithValidatorPath("/validate-twice").
+				WithDefaulterPath("/default-once").
+				WithHandler("/validate-twice", admission.HandlerFunc(func(ctx context.Context, req admission.Request) admission.Response {
+					return admission.Allowed("")
+				})).
+				Complete()
+			Expect(err).To(MatchError(ContainSubstring("already in use")))
+
+			By("checking that none of the webhooks of the failed builder were registered")
+			Expect(WebhookManagedBy(m).WithHandler("/default-once", admission.HandlerFunc(func(ctx context.Context, req admission.Request) admission.Response {
+				return admission.Allowed("")
+			})).Complete()).To(Succeed())
+		})
+
+		It("should return an error for a defaulter or a validator without For", func() {
+			By("creating a controller manager")
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			allow := admission.HandlerFunc(func(ctx context.Context, req admission.Request) admission.Response {
+				return admission.Allowed("")
+			})
+			err = WebhookManagedBy(m).
+				WithDefaulter(&TestCustomDefaulter{}).
+				WithHandler("/audit-nofor", allow).
+				Complete()
+			Expect(err).To(MatchError(ContainSubstring("must provide an object with For()")))
+
+			err = WebhookManagedBy(m).
+				WithValidatorPath("/validate-nofor").
+				WithHandler("/audit-nofor", allow).
+				Complete()
+			Expect(err).To(MatchError(ContainSubstring("must provide an object with For()")))
+
+			By("checking that the handler was not registered")
+			Expect(WebhookManagedBy(m).WithHandler("/audit-nofor", allow).Complete()).To(Succeed())
+		})
+
 		It("should scaffold a validating webhook if the type implements the Validator interface to validate deletes", func() {
 			By("creating a controller manager")
 			m, err := manager.New(cfg, manager.Options{})
@@ -515,3 +751,50 @@ func (dv *TestDefaultValidator) ValidateDelete() error {
 	}
 	return nil
 }
+
+// TestCustomDefaulter
+type TestCustomDefaulter struct{}
+
+var _ admission.CustomDefaulter = &TestCustomDefaulter{}
+
+func (*TestCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	v := obj.(*TestValidator)
+	if v.Replica < 2 {
+		v.Replica = 2
+	}
+	return nil
+}
+
+// TestCustomValidator
+type TestCustomValidator struct {
+	client client.Client
+}
+
+var _ admission.CustomValidator = &TestCustomValidator{}
+
+func (v *TestCustomValidator) InjectClient(c client.Client) error {
+	v.client = c
+	return nil
+}
+
+func (v *TestCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	if v.client == nil {
+		return errors.New("the client was not injected")
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if obj.(*TestValidator).Replica > 3 {
+		return fmt.Errorf("more than 3 replicas in %s", req.Namespace)
+	}
+	return nil
+}
+
+func (v *TestCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	return nil
+}
+
+func (v *TestCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informertest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/testing"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/internal"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ cache.Cache = &trackerCache{}
+
+// NewFakeCache returns a cache.Cache whose informers are driven by the object
+// tracker behind the given fake client (see fake.NewFakeClientWithScheme).
+// Objects created, updated or deleted through the client are delivered as
+// Create, Update and Delete events to the handlers registered on the cache's
+// informers, so a controller and its watches can run entirely in-process.
+//
+// Only typed objects registered in the given scheme are supported.
+func NewFakeCache(scheme *runtime.Scheme, c client.Client) (cache.Cache, error) {
+	tracker, err := fake.ObjectTrackerFor(c)
+	if err != nil {
+		return nil, err
+	}
+	return &trackerCache{
+		scheme:         scheme,
+		tracker:        tracker,
+		informersByGVK: map[schema.GroupVersionKind]*trackerInformer{},
+		startWait:      make(chan struct{}),
+	}, nil
+}
+
+// trackerInformer is an informer fed by the object tracker, along with a reader
+// for its store.
+type trackerInformer struct {
+	informer toolscache.SharedIndexInformer
+	reader   *internal.CacheReader
+}
+
+// trackerCache is a cache.Cache whose informers list and watch an object tracker.
+type trackerCache struct {
+	scheme  *runtime.Scheme
+	tracker testing.ObjectTracker
+
+	// mu guards access to the map and started
+	mu             sync.Mutex
+	informersByGVK map[schema.GroupVersionKind]*trackerInformer
+	started        bool
+	stop           <-chan struct{}
+
+	// startWait is a channel that is closed after the cache has been started.
+	startWait chan struct{}
+}
+
+// Get implements client.Reader
+func (c *trackerCache) Get(ctx context.Context, key client.ObjectKey, out runtime.Object) error {
+	gvk, err := apiutil.GVKForObject(out, c.scheme)
+	if err != nil {
+		return err
+	}
+	started, i, err := c.informerFor(gvk, out)
+	if err != nil {
+		return err
+	}
+	if !started {
+		return &cache.ErrCacheNotStarted{}
+	}
+	return i.reader.Get(ctx, key, out)
+}
+
+// List implements client.Reader
+func (c *trackerCache) List(ctx context.Context, out runtime.Object, opts ...client.ListOption) error {
+	gvk, err := apiutil.GVKForObject(out, c.scheme)
+	if err != nil {
+		return err
+	}
+	if !strings.HasSuffix(gvk.Kind, "List") {
+		return fmt.Errorf("non-list type %T (kind %q) passed as output", out, gvk)
+	}
+	// we need the non-list GVK, so chop off the "List" from the end of the kind
+	gvk.Kind = gvk.Kind[:len(gvk.Kind)-4]
+
+	itemsPtr, err := apimeta.GetItemsPtr(out)
+	if err != nil {
+		return err
+	}
+	elemType := reflect.Indirect(reflect.ValueOf(itemsPtr)).Type().Elem()
+	if elemType.Kind() != reflect.Ptr {
+		elemType = reflect.PtrTo(elemType)
+	}
+	obj, ok := reflect.New(elemType.Elem()).Interface().(runtime.Object)
+	if !ok {
+		return fmt.Errorf("cannot get cache for %T, its element is not a runtime.Object", out)
+	}
+
+	started, i, err := c.informerFor(gvk, obj)
+	if err != nil {
+		return err
+	}
+	if !started {
+		return &cache.ErrCacheNotStarted{}
+	}
+	return i.reader.List(ctx, out, opts...)
+}
+
+// GetInformer implements cache.Informers
+func (c *trackerCache) GetInformer(ctx context.Context, obj runtime.Object) (cache.Informer, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return nil, err
+	}
+	_, i, err := c.informerFor(gvk, obj)
+	if err != nil {
+		return nil, err
+	}
+	return i.informer, nil
+}
+
+// GetInformerForKind implements cache.Informers
+func (c *trackerCache) GetInformerForKind(ctx context.Context, gvk schema.GroupVersionKind) (cache.Informer, error) {
+	obj, err := c.scheme.New(gvk)
+	if err != nil {
+		return nil, err
+	}
+	_, i, err := c.informerFor(gvk, obj)
+	if err != nil {
+		return nil, err
+	}
+	return i.informer, nil
+}
+
+// Start implements cache.Informers.  It runs all the informers known to the
+// cache, and any created later, until the given channel is closed.
+func (c *trackerCache) Start(stop <-chan struct{}) error {
+	func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.stop = stop
+		for _, i := range c.informersByGVK {
+			go i.informer.Run(stop)
+		}
+		c.started = true
+		close(c.startWait)
+	}()
+	<-stop
+	return nil
+}
+
+// WaitForCacheSync implements cache.Informers
+func (c *trackerCache) WaitForCacheSync(stop <-chan struct{}) bool {
+	select {
+	case <-c.startWait:
+	case <-stop:
+		return false
+	}
+
+	c.mu.Lock()
+	syncedFuncs := make([]toolscache.InformerSynced, 0, len(c.informersByGVK))
+	for _, i := range c.informersByGVK {
+		syncedFuncs = append(syncedFuncs, i.informer.HasSynced)
+	}
+	c.mu.Unlock()
+
+	return toolscache.WaitForCacheSync(stop, syncedFuncs...)
+}
+
+// IndexField implements client.FieldIndexer
+func (c *trackerCache) IndexField(ctx context.Context, obj runtime.Object, field string, extractValue client.IndexerFunc) error {
+	informer, err := c.GetInformer(ctx, obj)
+	if err != nil {
+		return err
+	}
+	return informer.AddIndexers(toolscache.Indexers{internal.FieldIndexName(field): internal.FieldIndexFunc(extractValue)})
+}
+
+// informerFor returns the informer for the given kind, creating it (and
+// starting it, if the cache is already started) if necessary.
+func (c *trackerCache) informerFor(gvk schema.GroupVersionKind, obj runtime.Object) (bool, *trackerInformer, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if i, ok := c.informersByGVK[gvk]; ok {
+		return c.started, i, nil
+	}
+
+	ni := toolscache.NewSharedIndexInformer(c.listWatch(gvk), obj, 0, toolscache.Indexers{
+		toolscache.NamespaceIndex: toolscache.MetaNamespaceIndexFunc,
+	})
+	i := &trackerInformer{
+		informer: ni,
+		reader:   internal.NewCacheReader(ni.GetIndexer(), gvk),
+	}
+	c.informersByGVK[gvk] = i
+
+	if c.started {
+		go i.informer.Run(c.stop)
+	}
+	return c.started, i, nil
+}
+
+// listWatch returns a ListWatch over all namespaces of the tracker for the
+// given kind.
+func (c *trackerCache) listWatch(gvk schema.GroupVersionKind) *toolscache.ListWatch {
+	// This mirrors the resource guessing done by the fake client, so that
+	// we watch the same resource its writes are recorded under.
+	gvr, _ := apimeta.UnsafeGuessKindToResource(gvk)
+
+	// The tracker ignores resource versions, so any write made between a
+	// list and the following watch would be lost.  Open the watch before
+	// listing instead, and hand it out on the next call to WatchFunc.  Objects
+	// written in between are reported twice, which informers turn into a
+	// no-op update.
+	var mu sync.Mutex
+	var pending watch.Interface
+
+	return &toolscache.ListWatch{
+		ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+			w, err := c.tracker.Watch(gvr, metav1.NamespaceAll)
+			if err != nil {
+				return nil, err
+			}
+			list, err := c.tracker.List(gvr, gvk, metav1.NamespaceAll)
+			if err != nil {
+				w.Stop()
+				return nil, err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if pending != nil {
+				pending.Stop()
+			}
+			pending = w
+			return list, nil
+		},
+		WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if pending != nil {
+				w := pending
+				pending = nil
+				return w, nil
+			}
+			return c.tracker.Watch(gvr, metav1.NamespaceAll)
+		},
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informertest_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("NewFakeCache", func() {
+	var (
+		cl      client.Client
+		c       cache.Cache
+		stop    chan struct{}
+		ctx     = context.Background()
+		initial *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		initial = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "initial", Namespace: "default", Labels: map[string]string{"app": "a"}}}
+		cl = fake.NewFakeClientWithScheme(scheme.Scheme, initial)
+
+		var err error
+		c, err = informertest.NewFakeCache(scheme.Scheme, cl)
+		Expect(err).NotTo(HaveOccurred())
+		stop = make(chan struct{})
+	})
+
+	AfterEach(func() {
+		close(stop)
+	})
+
+	start := func() {
+		go func() {
+			defer GinkgoRecover()
+			Expect(c.Start(stop)).To(Succeed())
+		}()
+		Expect(c.WaitForCacheSync(stop)).To(BeTrue())
+	}
+
+	It("should error for clients not created by the fake package", func() {
+		_, err := informertest.NewFakeCache(scheme.Scheme, struct{ client.Client }{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error when read before being started", func() {
+		err := c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "initial"}, &corev1.Pod{})
+		Expect(err).To(Equal(&cache.ErrCacheNotStarted{}))
+	})
+
+	It("should serve reads from objects known to the client", func() {
+		_, err := c.GetInformer(ctx, &corev1.Pod{})
+		Expect(err).NotTo(HaveOccurred())
+		start()
+
+		pod := &corev1.Pod{}
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "initial"}, pod)).To(Succeed())
+		Expect(pod.Labels).To(Equal(map[string]string{"app": "a"}))
+
+		pods := &corev1.PodList{}
+		Expect(c.List(ctx, pods, client.InNamespace("default"), client.MatchingLabels{"app": "a"})).To(Succeed())
+		Expect(pods.Items).To(HaveLen(1))
+	})
+
+	It("should deliver events for writes made through the client", func() {
+		informer, err := c.GetInformer(ctx, &corev1.Pod{})
+		Expect(err).NotTo(HaveOccurred())
+
+		events := make(chan string, 10)
+		informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				events <- "add " + obj.(*corev1.Pod).Name
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				events <- "update " + newObj.(*corev1.Pod).Name
+			},
+			DeleteFunc: func(obj interface{}) {
+				events <- "delete " + obj.(*corev1.Pod).Name
+			},
+		})
+		start()
+		Eventually(events).Should(Receive(Equal("add initial")))
+
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "created", Namespace: "default"}}
+		Expect(cl.Create(ctx, pod)).To(Succeed())
+		Eventually(events).Should(Receive(Equal("add created")))
+
+		pod.Labels = map[string]string{"app": "b"}
+		Expect(cl.Update(ctx, pod)).To(Succeed())
+		Eventually(events).Should(Receive(Equal("update created")))
+		Eventually(func() map[string]string {
+			cached := &corev1.Pod{}
+			Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "created"}, cached)).To(Succeed())
+			return cached.Labels
+		}).Should(Equal(map[string]string{"app": "b"}))
+
+		Expect(cl.Delete(ctx, pod)).To(Succeed())
+		Eventually(events).Should(Receive(Equal("delete created")))
+	})
+
+	It("should start informers requested after the cache was started", func() {
+		start()
+		Expect(cl.Create(ctx, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "deploy", Namespace: "default"}})).To(Succeed())
+
+		Eventually(func() int {
+			deploys := &appsv1.DeploymentList{}
+			Expect(c.List(ctx, deploys)).To(Succeed())
+			return len(deploys.Items)
+		}).Should(Equal(1))
+	})
+
+	It("should support listing by indexed fields", func() {
+		Expect(c.IndexField(ctx, &corev1.Pod{}, "spec.nodeName", func(obj runtime.Object) []string {
+			return []string{obj.(*corev1.Pod).Spec.NodeName}
+		})).To(Succeed())
+		start()
+
+		Expect(cl.Create(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "scheduled", Namespace: "default"},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+		})).To(Succeed())
+
+		Eventually(func() []corev1.Pod {
+			pods := &corev1.PodList{}
+			Expect(c.List(ctx, pods, client.MatchingFields{"spec.nodeName": "node-1"})).To(Succeed())
+			return pods.Items
+		}).Should(HaveLen(1))
+	})
+})
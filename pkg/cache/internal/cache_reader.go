@@ -44,6 +44,12 @@ type CacheReader struct {
 	groupVersionKind schema.GroupVersionKind
 }
 
+// NewCacheReader returns a CacheReader that reads objects of the given
+// group-version-kind out of the given indexer.
+func NewCacheReader(indexer cache.Indexer, gvk schema.GroupVersionKind) *CacheReader {
+	return &CacheReader{indexer: indexer, groupVersionKind: gvk}
+}
+
 // Get checks the indexer for the object and writes a copy of it if found
 func (c *CacheReader) Get(_ context.Context, key client.ObjectKey, out runtime.Object) error {
 	storeKey := objectKeyToStoreKey(key)
@@ -172,6 +178,47 @@ func FieldIndexName(field string) string {
 	return "field:" + field
 }
 
+// FieldIndexFunc returns an index function that indexes objects by the values
+// returned from the given extractor.  The values will automatically be prefixed
+// with the namespace of the object, if present, and additionally indexed under
+// the "all namespaces" key so that they can be listed across namespaces.
+func FieldIndexFunc(extractor client.IndexerFunc) cache.IndexFunc {
+	return func(objRaw interface{}) ([]string, error) {
+		// TODO(directxman12): check if this is the correct type?
+		obj, isObj := objRaw.(runtime.Object)
+		if !isObj {
+			return nil, fmt.Errorf("object of type %T is not an Object", objRaw)
+		}
+		meta, err := apimeta.Accessor(obj)
+		if err != nil {
+			return nil, err
+		}
+		ns := meta.GetNamespace()
+
+		rawVals := extractor(obj)
+		var vals []string
+		if ns == "" {
+			// if we're not doubling the keys for the namespaced case, just re-use what was returned to us
+			vals = rawVals
+		} else {
+			// if we need to add non-namespaced versions too, double the length
+			vals = make([]string, len(rawVals)*2)
+		}
+		for i, rawVal := range rawVals {
+			// save a namespaced variant, so that we can ask
+			// "what are all the object matching a given index *in a given namespace*"
+			vals[i] = KeyToNamespacedKey(ns, rawVal)
+			if ns != "" {
+				// if we have a namespace, also inject a special index key for listing
+				// regardless of the object namespace
+				vals[i+len(rawVals)] = KeyToNamespacedKey("", rawVal)
+			}
+		}
+
+		return vals, nil
+	}
+}
+
 // noNamespaceNamespace is used as the "namespace" when we want to list across all namespaces
 const allNamespacesNamespace = "__all_namespaces"
 
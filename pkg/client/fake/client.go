@@ -81,6 +81,18 @@ func NewFakeClientWithScheme(clientScheme *runtime.Scheme, initObjs ...runtime.O
 	}
 }
 
+// ObjectTrackerFor returns the object tracker backing a client created by
+// NewFakeClient or NewFakeClientWithScheme.  Writes made through the client
+// are reported to watches opened on the tracker, which allows other test
+// fixtures (such as informertest.NewFakeCache) to observe them.
+func ObjectTrackerFor(c client.Client) (testing.ObjectTracker, error) {
+	fc, ok := c.(*fakeClient)
+	if !ok {
+		return nil, fmt.Errorf("client of type %T was not created by the fake package", c)
+	}
+	return fc.tracker, nil
+}
+
 func (t versionedTracker) Create(gvr schema.GroupVersionResource, obj runtime.Object, ns string) error {
 	accessor, err := meta.Accessor(obj)
 	if err != nil {
@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/internal/controller"
 	"sigs.k8s.io/controller-runtime/pkg/internal/log"
@@ -42,6 +43,12 @@ type Options struct {
 	// Defaults to MaxOfRateLimiter which has both overall and per-item rate limiting.
 	// The overall is a token bucket and the per-item is exponential.
 	RateLimiter ratelimiter.RateLimiter
+
+	// UsePriorityQueue makes the controller hand out requests by decreasing priority instead of
+	// in the order they were added, using a priorityqueue.PriorityQueue rate limited by RateLimiter.
+	// Use handler.WithPriority to assign priorities to the requests enqueued for events.
+	// The queue reports the same workqueue metrics as the default queue.
+	UsePriorityQueue bool
 }
 
 // Controller implements a Kubernetes API.  A Controller manages a work queue fed reconcile.Requests
@@ -105,6 +112,9 @@ func NewUnmanaged(name string, mgr manager.Manager, options Options) (Controller
 	c := &controller.Controller{
 		Do: options.Reconciler,
 		MakeQueue: func() workqueue.RateLimitingInterface {
+			if options.UsePriorityQueue {
+				return priorityqueue.NewNamed(options.RateLimiter, name)
+			}
 			return workqueue.NewNamedRateLimitingQueue(options.RateLimiter, name)
 		},
 		MaxConcurrentReconciles: options.MaxConcurrentReconciles,
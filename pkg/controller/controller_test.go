@@ -25,6 +25,8 @@ import (
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	internalcontroller "sigs.k8s.io/controller-runtime/pkg/internal/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
@@ -93,6 +95,25 @@ var _ = Describe("controller.Controller", func() {
 			close(done)
 		})
 
+		It("should use a priority queue with UsePriorityQueue", func(done Done) {
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			c, err := controller.New("c-priority", m, controller.Options{Reconciler: rec})
+			Expect(err).NotTo(HaveOccurred())
+			q := c.(*internalcontroller.Controller).MakeQueue()
+			defer q.ShutDown()
+			Expect(q).NotTo(BeAssignableToTypeOf(priorityqueue.New(nil)))
+
+			c, err = controller.New("c-priority-queue", m, controller.Options{Reconciler: rec, UsePriorityQueue: true})
+			Expect(err).NotTo(HaveOccurred())
+			pq := c.(*internalcontroller.Controller).MakeQueue()
+			defer pq.ShutDown()
+			Expect(pq).To(BeAssignableToTypeOf(priorityqueue.New(nil)))
+
+			close(done)
+		})
+
 		It("should not leak goroutines when stop", func(done Done) {
 			// TODO(directxman12): After closing the proper leaks on watch this must be reduced to 0
 			// The leaks currently come from the event-related code (as in corev1.Event).
@@ -0,0 +1,127 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllerutil
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// OwnerAPIVersionAnnotation is the annotation holding the apiVersion of the owner of an object.
+	OwnerAPIVersionAnnotation = "controller-runtime.sigs.k8s.io/owner-api-version"
+
+	// OwnerKindAnnotation is the annotation holding the kind of the owner of an object.
+	OwnerKindAnnotation = "controller-runtime.sigs.k8s.io/owner-kind"
+
+	// OwnerNamespaceAnnotation is the annotation holding the namespace of the owner of an
+	// object.  It is not set for cluster-scoped owners.
+	OwnerNamespaceAnnotation = "controller-runtime.sigs.k8s.io/owner-namespace"
+
+	// OwnerNameAnnotation is the annotation holding the name of the owner of an object.
+	OwnerNameAnnotation = "controller-runtime.sigs.k8s.io/owner-name"
+)
+
+// AnnotationOwner is the owner of an object, as recorded in its annotations.
+type AnnotationOwner struct {
+	// GroupVersionKind is the type of the owner.
+	schema.GroupVersionKind
+
+	// NamespacedName is the key of the owner.
+	types.NamespacedName
+}
+
+// SetOwnerAnnotations records owner as the owner of object in annotations of object.
+// Unlike owner references, annotations can refer to an owner in another namespace, or
+// to a cluster-scoped owner from a namespaced object, but they don't cause the object
+// to be garbage collected along with its owner.  Use them with
+// handler.EnqueueRequestForAnnotationOwner to reconcile the owner on changes to object.
+//
+// Since an object can only have one owner recorded in its annotations, it returns an
+// AlreadyOwnedError if object is already owned by another object.
+func SetOwnerAnnotations(owner, object metav1.Object, scheme *runtime.Scheme) error {
+	ro, ok := owner.(runtime.Object)
+	if !ok {
+		return fmt.Errorf("%T is not a runtime.Object, cannot call SetOwnerAnnotations", owner)
+	}
+	gvk, err := apiutil.GVKForObject(ro, scheme)
+	if err != nil {
+		return err
+	}
+	ownerKey := types.NamespacedName{Namespace: owner.GetNamespace(), Name: owner.GetName()}
+
+	if existing, ok := GetOwnerAnnotations(object); ok {
+		if existing.GroupKind() != gvk.GroupKind() || existing.NamespacedName != ownerKey {
+			return newAlreadyOwnedError(object, metav1.OwnerReference{
+				APIVersion: existing.GroupVersion().String(),
+				Kind:       existing.Kind,
+				Name:       existing.Name,
+			})
+		}
+	}
+
+	annotations := object.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[OwnerAPIVersionAnnotation] = gvk.GroupVersion().String()
+	annotations[OwnerKindAnnotation] = gvk.Kind
+	annotations[OwnerNameAnnotation] = ownerKey.Name
+	if ownerKey.Namespace != "" {
+		annotations[OwnerNamespaceAnnotation] = ownerKey.Namespace
+	} else {
+		delete(annotations, OwnerNamespaceAnnotation)
+	}
+	object.SetAnnotations(annotations)
+	return nil
+}
+
+// GetOwnerAnnotations returns the owner recorded in the annotations of object by
+// SetOwnerAnnotations, and false if there is none.
+func GetOwnerAnnotations(object metav1.Object) (AnnotationOwner, bool) {
+	annotations := object.GetAnnotations()
+	kind, name := annotations[OwnerKindAnnotation], annotations[OwnerNameAnnotation]
+	if kind == "" || name == "" {
+		return AnnotationOwner{}, false
+	}
+	gv, err := schema.ParseGroupVersion(annotations[OwnerAPIVersionAnnotation])
+	if err != nil {
+		return AnnotationOwner{}, false
+	}
+	return AnnotationOwner{
+		GroupVersionKind: gv.WithKind(kind),
+		NamespacedName:   types.NamespacedName{Namespace: annotations[OwnerNamespaceAnnotation], Name: name},
+	}, true
+}
+
+// RemoveOwnerAnnotations removes the owner recorded in the annotations of object, if any.
+func RemoveOwnerAnnotations(object metav1.Object) {
+	annotations := object.GetAnnotations()
+	if annotations == nil {
+		return
+	}
+	delete(annotations, OwnerAPIVersionAnnotation)
+	delete(annotations, OwnerKindAnnotation)
+	delete(annotations, OwnerNamespaceAnnotation)
+	delete(annotations, OwnerNameAnnotation)
+	object.SetAnnotations(annotations)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllerutil_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ = Describe("Owner annotations", func() {
+	var owner *rbacv1.ClusterRole
+	var object *corev1.ConfigMap
+
+	BeforeEach(func() {
+		owner = &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+		object = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "biz",
+			Name:        "baz",
+			Annotations: map[string]string{"other": "annotation"},
+		}}
+	})
+
+	It("should record a cluster-scoped owner of a namespaced object", func() {
+		Expect(controllerutil.SetOwnerAnnotations(owner, object, scheme.Scheme)).To(Succeed())
+		Expect(object.Annotations).To(Equal(map[string]string{
+			"other":                                  "annotation",
+			controllerutil.OwnerAPIVersionAnnotation: "rbac.authorization.k8s.io/v1",
+			controllerutil.OwnerKindAnnotation:       "ClusterRole",
+			controllerutil.OwnerNameAnnotation:       "foo",
+		}))
+
+		got, ok := controllerutil.GetOwnerAnnotations(object)
+		Expect(ok).To(BeTrue())
+		Expect(got).To(Equal(controllerutil.AnnotationOwner{
+			GroupVersionKind: schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"},
+			NamespacedName:   types.NamespacedName{Name: "foo"},
+		}))
+	})
+
+	It("should record an owner in another namespace", func() {
+		dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "foo"}}
+		Expect(controllerutil.SetOwnerAnnotations(dep, object, scheme.Scheme)).To(Succeed())
+
+		got, ok := controllerutil.GetOwnerAnnotations(object)
+		Expect(ok).To(BeTrue())
+		Expect(got.GroupKind()).To(Equal(schema.GroupKind{Group: "apps", Kind: "Deployment"}))
+		Expect(got.NamespacedName).To(Equal(types.NamespacedName{Namespace: "other", Name: "foo"}))
+	})
+
+	It("should be idempotent, and refuse to change the owner", func() {
+		Expect(controllerutil.SetOwnerAnnotations(owner, object, scheme.Scheme)).To(Succeed())
+		Expect(controllerutil.SetOwnerAnnotations(owner, object, scheme.Scheme)).To(Succeed())
+
+		other := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "bar"}}
+		err := controllerutil.SetOwnerAnnotations(other, object, scheme.Scheme)
+		Expect(err).To(BeAssignableToTypeOf(&controllerutil.AlreadyOwnedError{}))
+		Expect(object.Annotations[controllerutil.OwnerNameAnnotation]).To(Equal("foo"))
+	})
+
+	It("should remove the owner", func() {
+		Expect(controllerutil.SetOwnerAnnotations(owner, object, scheme.Scheme)).To(Succeed())
+		controllerutil.RemoveOwnerAnnotations(object)
+		Expect(object.Annotations).To(Equal(map[string]string{"other": "annotation"}))
+		_, ok := controllerutil.GetOwnerAnnotations(object)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should return an error if the owner type isn't known", func() {
+		Expect(controllerutil.SetOwnerAnnotations(&corev1.ConfigMap{}, object, runtime.NewScheme())).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,26 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package priorityqueue provides a work queue handing out the requests of a Controller by
+decreasing priority, so that the requests enqueued for changes made by users aren't
+delayed by the thousands of requests enqueued for the initial list of objects after
+a restart.
+
+Controllers use it when created with controller.Options.UsePriorityQueue, and event
+handlers assign priorities to the requests they enqueue with handler.WithPriority.
+*/
+package priorityqueue
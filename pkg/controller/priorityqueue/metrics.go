@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priorityqueue
+
+import (
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+// This file mirrors the metrics of the client-go workqueues, so that controllers
+// report the same metrics whichever queue they use.
+
+// unfinishedWorkUpdatePeriod is the period the metrics of the items being processed
+// are updated with, like in client-go.
+const unfinishedWorkUpdatePeriod = 500 * time.Millisecond
+
+// queueMetrics reports the workqueue metrics of a queue.  Its methods must be called
+// with the lock of the queue held.
+type queueMetrics struct {
+	depth                   workqueue.GaugeMetric
+	adds                    workqueue.CounterMetric
+	latency                 workqueue.HistogramMetric
+	workDuration            workqueue.HistogramMetric
+	unfinishedWorkSeconds   workqueue.SettableGaugeMetric
+	longestRunningProcessor workqueue.SettableGaugeMetric
+	retries                 workqueue.CounterMetric
+
+	// addTimes are the times the items waiting in the queue were added at
+	addTimes map[interface{}]time.Time
+	// processingStartTimes are the times the items being processed were handed out at
+	processingStartTimes map[interface{}]time.Time
+}
+
+func newQueueMetrics(provider workqueue.MetricsProvider, name string) *queueMetrics {
+	return &queueMetrics{
+		depth:                   provider.NewDepthMetric(name),
+		adds:                    provider.NewAddsMetric(name),
+		latency:                 provider.NewLatencyMetric(name),
+		workDuration:            provider.NewWorkDurationMetric(name),
+		unfinishedWorkSeconds:   provider.NewUnfinishedWorkSecondsMetric(name),
+		longestRunningProcessor: provider.NewLongestRunningProcessorSecondsMetric(name),
+		retries:                 provider.NewRetriesMetric(name),
+		addTimes:                map[interface{}]time.Time{},
+		processingStartTimes:    map[interface{}]time.Time{},
+	}
+}
+
+func (m *queueMetrics) add(item interface{}) {
+	m.adds.Inc()
+	m.depth.Inc()
+	if _, ok := m.addTimes[item]; !ok {
+		m.addTimes[item] = time.Now()
+	}
+}
+
+func (m *queueMetrics) get(item interface{}) {
+	m.depth.Dec()
+	now := time.Now()
+	m.processingStartTimes[item] = now
+	if start, ok := m.addTimes[item]; ok {
+		m.latency.Observe(now.Sub(start).Seconds())
+		delete(m.addTimes, item)
+	}
+}
+
+func (m *queueMetrics) done(item interface{}) {
+	if start, ok := m.processingStartTimes[item]; ok {
+		m.workDuration.Observe(time.Since(start).Seconds())
+		delete(m.processingStartTimes, item)
+	}
+}
+
+func (m *queueMetrics) retry() {
+	m.retries.Inc()
+}
+
+func (m *queueMetrics) updateUnfinishedWork() {
+	var total, longest float64
+	now := time.Now()
+	for _, start := range m.processingStartTimes {
+		age := now.Sub(start).Seconds()
+		total += age
+		if age > longest {
+			longest = age
+		}
+	}
+	m.unfinishedWorkSeconds.Set(total)
+	m.longestRunningProcessor.Set(longest)
+}
+
+// noopMetricsProvider provides metrics which report nothing.
+type noopMetricsProvider struct{}
+
+func (noopMetricsProvider) NewDepthMetric(string) workqueue.GaugeMetric       { return noopMetric{} }
+func (noopMetricsProvider) NewAddsMetric(string) workqueue.CounterMetric      { return noopMetric{} }
+func (noopMetricsProvider) NewLatencyMetric(string) workqueue.HistogramMetric { return noopMetric{} }
+func (noopMetricsProvider) NewWorkDurationMetric(string) workqueue.HistogramMetric {
+	return noopMetric{}
+}
+func (noopMetricsProvider) NewUnfinishedWorkSecondsMetric(string) workqueue.SettableGaugeMetric {
+	return noopMetric{}
+}
+func (noopMetricsProvider) NewLongestRunningProcessorSecondsMetric(string) workqueue.SettableGaugeMetric {
+	return noopMetric{}
+}
+func (noopMetricsProvider) NewRetriesMetric(string) workqueue.CounterMetric { return noopMetric{} }
+
+type noopMetric struct{}
+
+func (noopMetric) Inc()            {}
+func (noopMetric) Dec()            {}
+func (noopMetric) Set(float64)     {}
+func (noopMetric) Observe(float64) {}
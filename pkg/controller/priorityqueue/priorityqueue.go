@@ -0,0 +1,341 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priorityqueue
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
+)
+
+const (
+	// LowPriority is the priority to use for requests which can wait, e.g. those
+	// enqueued for the initial list of objects or for periodic resyncs.
+	LowPriority = -100
+
+	// DefaultPriority is the priority of the requests added without a priority.
+	DefaultPriority = 0
+
+	// HighPriority is the priority to use for urgent requests.
+	HighPriority = 100
+)
+
+// PriorityQueue is a workqueue.RateLimitingInterface handing out its items by decreasing
+// priority, and in the order they were added for items of the same priority.
+//
+// Like the workqueues of client-go, an item is never handed out twice concurrently, and an
+// item added while being processed is handed out again once Done is called for it.  Adding
+// an item which is already queued only raises its priority.
+type PriorityQueue interface {
+	workqueue.RateLimitingInterface
+
+	// AddWithPriority adds an item to the queue with the given priority.
+	AddWithPriority(item interface{}, priority int)
+}
+
+// New returns a new PriorityQueue rate limiting its items with the given RateLimiter.
+// Items added with Add get the DefaultPriority, while items added with AddAfter or
+// AddRateLimited, e.g. the requests requeued by a Controller, keep the priority they
+// are being processed or queued with.  The queue reports no metrics, see NewNamed.
+func New(rateLimiter ratelimiter.RateLimiter) PriorityQueue {
+	return newQueue(rateLimiter, newQueueMetrics(noopMetricsProvider{}, ""))
+}
+
+// NewNamed returns a new PriorityQueue like New, which reports the same workqueue
+// metrics as the client-go workqueues under the given name.
+func NewNamed(rateLimiter ratelimiter.RateLimiter, name string) PriorityQueue {
+	q := newQueue(rateLimiter, newQueueMetrics(metrics.WorkqueueMetricsProvider(), name))
+	go q.updateUnfinishedWorkLoop()
+	return q
+}
+
+func newQueue(rateLimiter ratelimiter.RateLimiter, metrics *queueMetrics) *priorityQueue {
+	q := &priorityQueue{
+		rateLimiter: rateLimiter,
+		metrics:     metrics,
+		queued:      map[interface{}]*entry{},
+		processing:  map[interface{}]int{},
+		dirty:       map[interface{}]int{},
+		timers:      map[*time.Timer]struct{}{},
+		stopped:     make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+var _ PriorityQueue = &priorityQueue{}
+
+// priorityQueue implements PriorityQueue with a heap of entries.
+type priorityQueue struct {
+	rateLimiter ratelimiter.RateLimiter
+
+	// stopped is closed once the queue shuts down
+	stopped chan struct{}
+
+	// mu guards all the fields below, cond is signaled when an entry is pushed or the
+	// queue shuts down
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	// entries are the items waiting to be handed out
+	entries entryHeap
+	// queued indexes the entries by item
+	queued map[interface{}]*entry
+	// processing are the items handed out and not Done yet, with their priority
+	processing map[interface{}]int
+	// dirty are the items added while being processed, with their priority
+	dirty map[interface{}]int
+	// timers are the pending timers of AddAfter
+	timers map[*time.Timer]struct{}
+	// metrics are the workqueue metrics of the queue
+	metrics *queueMetrics
+
+	// seq orders the entries of the same priority
+	seq          uint64
+	shuttingDown bool
+}
+
+// Add implements workqueue.Interface.
+func (q *priorityQueue) Add(item interface{}) {
+	q.AddWithPriority(item, DefaultPriority)
+}
+
+// AddWithPriority implements PriorityQueue.
+func (q *priorityQueue) AddWithPriority(item interface{}, priority int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.add(item, priority)
+}
+
+// add adds item with the given priority.  It must be called with mu held.
+func (q *priorityQueue) add(item interface{}, priority int) {
+	if q.shuttingDown {
+		return
+	}
+	if _, ok := q.processing[item]; ok {
+		p, ok := q.dirty[item]
+		if !ok {
+			q.metrics.add(item)
+		}
+		if !ok || priority > p {
+			q.dirty[item] = priority
+		}
+		return
+	}
+	if e, ok := q.queued[item]; ok {
+		if priority > e.priority {
+			e.priority = priority
+			heap.Fix(&q.entries, e.index)
+		}
+		return
+	}
+	q.metrics.add(item)
+	q.push(item, priority)
+}
+
+// push queues a new entry for item, and wakes up a waiting Get.  It must be called with mu held.
+func (q *priorityQueue) push(item interface{}, priority int) {
+	q.seq++
+	e := &entry{item: item, priority: priority, seq: q.seq}
+	heap.Push(&q.entries, e)
+	q.queued[item] = e
+	q.cond.Signal()
+}
+
+// Len implements workqueue.Interface.
+func (q *priorityQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.entries)
+}
+
+// Get implements workqueue.Interface.  It blocks until an item can be handed out, or
+// the queue shuts down and has no more items.
+func (q *priorityQueue) Get() (interface{}, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.entries) == 0 && !q.shuttingDown {
+		q.cond.Wait()
+	}
+	if len(q.entries) == 0 {
+		return nil, true
+	}
+
+	e := heap.Pop(&q.entries).(*entry)
+	delete(q.queued, e.item)
+	q.processing[e.item] = e.priority
+	q.metrics.get(e.item)
+	return e.item, false
+}
+
+// Done implements workqueue.Interface.
+func (q *priorityQueue) Done(item interface{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.processing, item)
+	q.metrics.done(item)
+	if priority, ok := q.dirty[item]; ok {
+		delete(q.dirty, item)
+		q.push(item, priority)
+	}
+}
+
+// ShutDown implements workqueue.Interface.
+func (q *priorityQueue) ShutDown() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.shuttingDown {
+		close(q.stopped)
+	}
+	q.shuttingDown = true
+	for t := range q.timers {
+		t.Stop()
+	}
+	q.timers = map[*time.Timer]struct{}{}
+	q.cond.Broadcast()
+}
+
+// ShuttingDown implements workqueue.Interface.
+func (q *priorityQueue) ShuttingDown() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.shuttingDown
+}
+
+// AddAfter implements workqueue.DelayingInterface.  The item is added with the
+// priority it is being processed or queued with, so that requeues keep their priority.
+func (q *priorityQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.shuttingDown {
+		return
+	}
+	priority := q.priorityOf(item)
+	if duration <= 0 {
+		q.add(item, priority)
+		return
+	}
+	var t *time.Timer
+	t = time.AfterFunc(duration, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		delete(q.timers, t)
+		q.add(item, priority)
+	})
+	q.timers[t] = struct{}{}
+}
+
+// priorityOf returns the priority item is being processed or queued with, or the
+// DefaultPriority if it is neither.  It must be called with mu held.
+func (q *priorityQueue) priorityOf(item interface{}) int {
+	if e, ok := q.queued[item]; ok {
+		return e.priority
+	}
+	priority, ok := q.processing[item]
+	if !ok {
+		return DefaultPriority
+	}
+	if p, ok := q.dirty[item]; ok && p > priority {
+		priority = p
+	}
+	return priority
+}
+
+// AddRateLimited implements workqueue.RateLimitingInterface, keeping the priority of
+// the item like AddAfter.
+func (q *priorityQueue) AddRateLimited(item interface{}) {
+	q.metrics.retry()
+	q.AddAfter(item, q.rateLimiter.When(item))
+}
+
+// Forget implements workqueue.RateLimitingInterface.
+func (q *priorityQueue) Forget(item interface{}) {
+	q.rateLimiter.Forget(item)
+}
+
+// NumRequeues implements workqueue.RateLimitingInterface.
+func (q *priorityQueue) NumRequeues(item interface{}) int {
+	return q.rateLimiter.NumRequeues(item)
+}
+
+// updateUnfinishedWorkLoop updates the metrics of the items being processed until the
+// queue shuts down.
+func (q *priorityQueue) updateUnfinishedWorkLoop() {
+	t := time.NewTicker(unfinishedWorkUpdatePeriod)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			q.mu.Lock()
+			q.metrics.updateUnfinishedWork()
+			q.mu.Unlock()
+		case <-q.stopped:
+			return
+		}
+	}
+}
+
+// entry is an item waiting in the queue.
+type entry struct {
+	item     interface{}
+	priority int
+	seq      uint64
+	// index of the entry in the heap, maintained by entryHeap
+	index int
+}
+
+// entryHeap implements heap.Interface, with the entry of highest priority, and lowest
+// sequence number among them, first.
+type entryHeap []*entry
+
+func (h entryHeap) Len() int { return len(h) }
+
+func (h entryHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h entryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *entryHeap) Push(x interface{}) {
+	e := x.(*entry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *entryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return e
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priorityqueue_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var _ = Describe("PriorityQueue", func() {
+	var q priorityqueue.PriorityQueue
+
+	BeforeEach(func() {
+		q = priorityqueue.New(workqueue.NewItemExponentialFailureRateLimiter(10*time.Millisecond, time.Second))
+	})
+
+	AfterEach(func() {
+		q.ShutDown()
+	})
+
+	get := func() interface{} {
+		item, shutdown := q.Get()
+		Expect(shutdown).To(BeFalse())
+		q.Done(item)
+		return item
+	}
+
+	It("should hand out items by decreasing priority, then in the order they were added", func() {
+		q.AddWithPriority("low-1", priorityqueue.LowPriority)
+		q.Add("default-1")
+		q.AddWithPriority("high-1", priorityqueue.HighPriority)
+		q.AddWithPriority("low-2", priorityqueue.LowPriority)
+		q.AddWithPriority("high-2", priorityqueue.HighPriority)
+		q.Add("default-2")
+		Expect(q.Len()).To(Equal(6))
+
+		var items []interface{}
+		for q.Len() > 0 {
+			items = append(items, get())
+		}
+		Expect(items).To(Equal([]interface{}{"high-1", "high-2", "default-1", "default-2", "low-1", "low-2"}))
+	})
+
+	It("should raise the priority of an item added again while queued", func() {
+		q.AddWithPriority("foo", priorityqueue.LowPriority)
+		q.Add("bar")
+		q.AddWithPriority("foo", priorityqueue.HighPriority)
+		q.AddWithPriority("bar", priorityqueue.LowPriority)
+		Expect(q.Len()).To(Equal(2))
+
+		Expect(get()).To(Equal("foo"))
+		Expect(get()).To(Equal("bar"))
+	})
+
+	It("should hand out an item added while processed again once done, with its highest priority", func() {
+		q.Add("foo")
+		item, _ := q.Get()
+
+		q.AddWithPriority("foo", priorityqueue.LowPriority)
+		q.AddWithPriority("foo", priorityqueue.HighPriority)
+		q.Add("bar")
+		Expect(q.Len()).To(Equal(1))
+
+		q.Done(item)
+		Expect(q.Len()).To(Equal(2))
+		Expect(get()).To(Equal("foo"))
+		Expect(get()).To(Equal("bar"))
+	})
+
+	It("should add items after the given duration", func() {
+		q.AddAfter("foo", 50*time.Millisecond)
+		Expect(q.Len()).To(Equal(0))
+		Eventually(q.Len).Should(Equal(1))
+
+		q.AddAfter("bar", 0)
+		Expect(q.Len()).To(Equal(2))
+	})
+
+	It("should rate limit items", func() {
+		q.AddRateLimited("foo")
+		q.AddRateLimited("foo")
+		Expect(q.NumRequeues("foo")).To(Equal(2))
+		Eventually(q.Len).Should(Equal(1))
+
+		q.Forget("foo")
+		Expect(q.NumRequeues("foo")).To(Equal(0))
+	})
+
+	It("should requeue items with the priority they were handed out with", func() {
+		q.AddWithPriority("low-1", priorityqueue.LowPriority)
+		q.AddWithPriority("low-2", priorityqueue.LowPriority)
+		q.AddWithPriority("high", priorityqueue.HighPriority)
+
+		By("keeping a high priority")
+		item, _ := q.Get()
+		Expect(item).To(Equal("high"))
+		q.AddAfter("high", 10*time.Millisecond)
+		q.Done(item)
+		q.Add("default")
+		Eventually(q.Len).Should(Equal(4))
+		Expect(get()).To(Equal("high"))
+		Expect(get()).To(Equal("default"))
+
+		By("not jumping ahead of the items of a low priority")
+		item, _ = q.Get()
+		Expect(item).To(Equal("low-1"))
+		q.AddRateLimited("low-1")
+		q.Done(item)
+		Eventually(q.Len).Should(Equal(2))
+		Expect(get()).To(Equal("low-2"))
+		Expect(get()).To(Equal("low-1"))
+	})
+
+	It("should unblock Get and drop new items once shut down", func() {
+		q.Add("foo")
+		q.ShutDown()
+		Expect(q.ShuttingDown()).To(BeTrue())
+
+		q.Add("bar")
+		q.AddAfter("baz", 10*time.Millisecond)
+		Consistently(q.Len, 50*time.Millisecond).Should(Equal(1))
+
+		item, shutdown := q.Get()
+		Expect(item).To(Equal("foo"))
+		Expect(shutdown).To(BeFalse())
+
+		_, shutdown = q.Get()
+		Expect(shutdown).To(BeTrue())
+	})
+
+	It("should hand out items to a blocked Get", func() {
+		got := make(chan interface{})
+		go func() {
+			item, _ := q.Get()
+			got <- item
+		}()
+		Consistently(got).ShouldNot(Receive())
+
+		q.Add("foo")
+		Eventually(got).Should(Receive(Equal("foo")))
+	})
+
+	It("should report the workqueue metrics under its name", func() {
+		metric := func(name string) float64 {
+			families, err := metrics.Registry.Gather()
+			Expect(err).NotTo(HaveOccurred())
+			for _, f := range families {
+				if f.GetName() != "workqueue_"+name {
+					continue
+				}
+				for _, m := range f.GetMetric() {
+					if len(m.GetLabel()) != 1 || m.GetLabel()[0].GetValue() != "priority-metrics" {
+						continue
+					}
+					switch {
+					case m.Gauge != nil:
+						return m.GetGauge().GetValue()
+					case m.Counter != nil:
+						return m.GetCounter().GetValue()
+					default:
+						return float64(m.GetHistogram().GetSampleCount())
+					}
+				}
+			}
+			return 0
+		}
+
+		named := priorityqueue.NewNamed(workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, time.Second), "priority-metrics")
+		defer named.ShutDown()
+
+		named.Add("foo")
+		named.AddWithPriority("bar", priorityqueue.HighPriority)
+		named.Add("foo")
+		Expect(metric(metrics.AddsKey)).To(Equal(2.0))
+		Expect(metric(metrics.DepthKey)).To(Equal(2.0))
+
+		item, _ := named.Get()
+		Expect(item).To(Equal("bar"))
+		Expect(metric(metrics.DepthKey)).To(Equal(1.0))
+		Expect(metric(metrics.QueueLatencyKey)).To(Equal(1.0))
+		Eventually(func() float64 { return metric(metrics.LongestRunningProcessorKey) }).Should(BeNumerically(">", 0))
+		named.Done(item)
+		Expect(metric(metrics.WorkDurationKey)).To(Equal(1.0))
+
+		named.AddRateLimited("baz")
+		Expect(metric(metrics.RetriesKey)).To(Equal(1.0))
+	})
+})
@@ -18,9 +18,13 @@ limitations under the License.
 //
 // Control plane binaries (etcd and kube-apiserver) are loaded by default from
 // /usr/local/kubebuilder/bin.  This can be overridden by setting the
-// KUBEBUILDER_ASSETS environment variable, or by directly creating a
-// ControlPlane for the Environment to use.
+// KUBEBUILDER_ASSETS environment variable or Environment.BinaryAssetsDirectory,
+// or by directly creating a ControlPlane for the Environment to use.  The
+// setup package and the setup-envtest tool can download and manage binaries
+// for specific Kubernetes versions.
 //
 // Environment can also be configured to work with an existing cluster, and
-// simply load CRDs and provide client configuration.
+// simply load CRDs and provide client configuration, or to share its control
+// plane with the test suites of other packages (see
+// Environment.ShareControlPlane).
 package envtest
@@ -18,14 +18,20 @@ package envtest
 
 import (
 	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -807,4 +813,150 @@ var _ = Describe("Test", func() {
 			close(done)
 		}, 30)
 	})
+
+	Describe("BinaryAssetsDirectory", func() {
+		It("should take precedence over the KUBEBUILDER_ASSETS environment variable", func() {
+			env := &Environment{BinaryAssetsDirectory: "/some/assets"}
+			Expect(env.defaultAssetPath("etcd")).To(Equal("/some/assets/etcd"))
+		})
+
+		It("should default to the KUBEBUILDER_ASSETS environment variable", func() {
+			expected := os.Getenv(envKubebuilderPath)
+			if expected == "" {
+				expected = defaultKubebuilderPath
+			}
+			env := &Environment{}
+			Expect(env.defaultAssetPath("etcd")).To(Equal(filepath.Join(expected, "etcd")))
+		})
+	})
+
+	Describe("AddUser", func() {
+		It("should only allow what the user was granted through RBAC", func(done Done) {
+			admin, err := client.New(env.Config, client.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			role := &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "configmap-reader"},
+				Rules: []rbacv1.PolicyRule{{
+					APIGroups: []string{""},
+					Resources: []string{"configmaps"},
+					Verbs:     []string{"get", "list"},
+				}},
+			}
+			Expect(admin.Create(context.TODO(), role)).To(Succeed())
+			binding := &rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "configmap-readers"},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: role.Name},
+				Subjects:   []rbacv1.Subject{{APIGroup: rbacv1.GroupName, Kind: rbacv1.GroupKind, Name: "readers"}},
+			}
+			Expect(admin.Create(context.TODO(), binding)).To(Succeed())
+			defer func() {
+				Expect(admin.Delete(context.TODO(), binding)).To(Succeed())
+				Expect(admin.Delete(context.TODO(), role)).To(Succeed())
+			}()
+
+			cfg, err := env.AddUser("reader", []string{"readers"})
+			Expect(err).NotTo(HaveOccurred())
+			reader, err := client.New(cfg, client.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("listing configmaps, which the user is allowed to do")
+			Expect(reader.List(context.TODO(), &corev1.ConfigMapList{}, client.InNamespace("default"))).To(Succeed())
+
+			By("creating a configmap, which the user is not allowed to do")
+			cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "forbidden", Namespace: "default"}}
+			err = reader.Create(context.TODO(), cm)
+			Expect(apierrors.IsForbidden(err)).To(BeTrue(), "expected a forbidden error, got %v", err)
+
+			close(done)
+		}, 30)
+
+		It("should allow everything to members of system:masters", func(done Done) {
+			cfg, err := env.AddUser("superuser", []string{"system:masters"})
+			Expect(err).NotTo(HaveOccurred())
+			superuser, err := client.New(cfg, client.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "superuser-configmap", Namespace: "default"}}
+			Expect(superuser.Create(context.TODO(), cm)).To(Succeed())
+			Expect(superuser.Delete(context.TODO(), cm)).To(Succeed())
+
+			close(done)
+		}, 30)
+
+		It("should not be supported with an existing cluster", func() {
+			useExisting := true
+			env := &Environment{UseExistingCluster: &useExisting}
+			_, err := env.AddUser("someone", nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("ShareControlPlane", func() {
+		var dir string
+		share := true
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "envtest-shared-")
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("should reuse the control plane until its last user stops", func(done Done) {
+			first := &Environment{ShareControlPlane: &share, SharedControlPlaneDir: dir}
+			_, err := first.Start()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filepath.Join(dir, sharedKubeconfigFile)).To(BeAnExistingFile())
+
+			second := &Environment{ShareControlPlane: &share, SharedControlPlaneDir: dir}
+			_, err = second.Start()
+			Expect(err).NotTo(HaveOccurred())
+
+			By("attaching to the same API server, in a different namespace")
+			Expect(second.Config.Host).To(Equal(first.Config.Host))
+			Expect(first.Namespace).NotTo(BeEmpty())
+			Expect(second.Namespace).NotTo(BeEmpty())
+			Expect(second.Namespace).NotTo(Equal(first.Namespace))
+
+			By("keeping the control plane running while it has users")
+			Expect(first.Stop()).To(Succeed())
+			c, err := client.New(second.Config, client.Options{})
+			Expect(err).NotTo(HaveOccurred())
+			cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: second.Namespace}}
+			Expect(c.Create(context.TODO(), cm)).To(Succeed())
+
+			By("tearing it down when the last user stops")
+			Expect(second.Stop()).To(Succeed())
+			Expect(filepath.Join(dir, sharedStateFile)).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(dir, sharedKubeconfigFile)).NotTo(BeAnExistingFile())
+
+			close(done)
+		}, 60)
+
+		It("should replace a control plane whose users are gone", func(done Done) {
+			By("publishing a control plane used by a process that exited")
+			exited := exec.Command("true")
+			Expect(exited.Run()).To(Succeed())
+			stale := &sharedControlPlane{
+				APIServerURL: "http://127.0.0.1:1",
+				Users:        []int{exited.Process.Pid},
+			}
+			Expect(writeSharedControlPlane(dir, stale)).To(Succeed())
+
+			env := &Environment{ShareControlPlane: &share, SharedControlPlaneDir: dir}
+			_, err := env.Start()
+			Expect(err).NotTo(HaveOccurred())
+
+			shared, err := readSharedControlPlane(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(shared.APIServerURL).NotTo(Equal(stale.APIServerURL))
+			Expect(shared.Users).To(ConsistOf(os.Getpid()))
+
+			Expect(env.Stop()).To(Succeed())
+			close(done)
+		}, 60)
+	})
 })
@@ -43,6 +43,8 @@ It's possible to override some defaults, by setting the following environment va
 	KUBEBUILDER_CONTROLPLANE_START_TIMEOUT (string supported by time.ParseDuration): timeout for test control plane to start. Defaults to 20s.
 	KUBEBUILDER_CONTROLPLANE_STOP_TIMEOUT (string supported by time.ParseDuration): timeout for test control plane to start. Defaults to 20s.
 	KUBEBUILDER_ATTACH_CONTROL_PLANE_OUTPUT (boolean): if set to true, the control plane's stdout and stderr are attached to os.Stdout and os.Stderr
+	KUBEBUILDER_SHARE_CONTROL_PLANE (boolean): if set to true, the control plane is shared with other test processes
+	KUBEBUILDER_SHARED_CONTROL_PLANE_DIR (string): directory in which a shared control plane is published. Defaults to kubebuilder-envtest-shared in the temporary directory.
 
 */
 const (
@@ -54,6 +56,8 @@ const (
 	envStartTimeout        = "KUBEBUILDER_CONTROLPLANE_START_TIMEOUT"
 	envStopTimeout         = "KUBEBUILDER_CONTROLPLANE_STOP_TIMEOUT"
 	envAttachOutput        = "KUBEBUILDER_ATTACH_CONTROL_PLANE_OUTPUT"
+	envShareControlPlane   = "KUBEBUILDER_SHARE_CONTROL_PLANE"
+	envSharedDir           = "KUBEBUILDER_SHARED_CONTROL_PLANE_DIR"
 	defaultKubebuilderPath = "/usr/local/kubebuilder/bin"
 	StartTimeout           = 60
 	StopTimeout            = 60
@@ -63,13 +67,15 @@ const (
 )
 
 // Default binary path for test framework
-func defaultAssetPath(binary string) string {
-	assetPath := os.Getenv(envKubebuilderPath)
+func (te *Environment) defaultAssetPath(binary string) string {
+	assetPath := te.BinaryAssetsDirectory
+	if assetPath == "" {
+		assetPath = os.Getenv(envKubebuilderPath)
+	}
 	if assetPath == "" {
 		assetPath = defaultKubebuilderPath
 	}
 	return filepath.Join(assetPath, binary)
-
 }
 
 // ControlPlane is the re-exported ControlPlane type from the internal integration package
@@ -128,6 +134,14 @@ type Environment struct {
 	// environment variable or 20 seconds if unspecified
 	ControlPlaneStopTimeout time.Duration
 
+	// BinaryAssetsDirectory is the directory containing the binaries of the
+	// control plane (etcd, kube-apiserver and kubectl), such as one returned by
+	// the setup package for a given Kubernetes version.  It defaults to the
+	// KUBEBUILDER_ASSETS environment variable, or /usr/local/kubebuilder/bin if
+	// unset.  The TEST_ASSET_* environment variables still take precedence for
+	// individual binaries.
+	BinaryAssetsDirectory string
+
 	// KubeAPIServerFlags is the set of flags passed while starting the api server.
 	KubeAPIServerFlags []string
 
@@ -135,13 +149,39 @@ type Environment struct {
 	// Enable this to get more visibility of the testing control plane.
 	// It respect KUBEBUILDER_ATTACH_CONTROL_PLANE_OUTPUT environment variable.
 	AttachControlPlaneOutput bool
+
+	// ShareControlPlane indicates that this Environment should share its
+	// control plane with the Environments of other test processes, such as
+	// the suites of other packages run in parallel by go test.  The first
+	// Environment to start launches the control plane and publishes it in
+	// SharedControlPlaneDir; the following ones attach to it, and the last one
+	// to stop tears it down.  Each Environment gets its own Namespace to run
+	// its tests in.
+	//
+	// When sharing a control plane, the control plane output is written to log
+	// files in its directories instead of being attached, CRDs are not
+	// uninstalled on Stop, and AddUser is only supported by the Environment
+	// that launched the control plane.  Sharing is not supported on Windows.
+	//
+	// It defaults to the KUBEBUILDER_SHARE_CONTROL_PLANE environment variable.
+	ShareControlPlane *bool
+
+	// SharedControlPlaneDir is the directory in which a shared control plane
+	// is published, along with a kubeconfig to access it.  It defaults to the
+	// KUBEBUILDER_SHARED_CONTROL_PLANE_DIR environment variable, or a
+	// kubebuilder-envtest-shared directory in the temporary directory.
+	SharedControlPlaneDir string
+
+	// Namespace is the namespace created for the exclusive use of this
+	// Environment when sharing a control plane.  It's deleted by Stop.
+	Namespace string
 }
 
 // Stop stops a running server.
 // Previously installed CRDs, as listed in CRDInstallOptions.CRDs, will be uninstalled
-// if CRDInstallOptions.CleanUpAfterUse are set to true.
+// if CRDInstallOptions.CleanUpAfterUse are set to true, unless the control plane is shared.
 func (te *Environment) Stop() error {
-	if te.CRDInstallOptions.CleanUpAfterUse {
+	if te.CRDInstallOptions.CleanUpAfterUse && !te.shareControlPlane() {
 		if err := UninstallCRDs(te.Config, te.CRDInstallOptions); err != nil {
 			return err
 		}
@@ -153,6 +193,9 @@ func (te *Environment) Stop() error {
 	if err != nil {
 		return err
 	}
+	if te.shareControlPlane() {
+		return te.stopSharedControlPlane()
+	}
 	return te.ControlPlane.Stop()
 }
 
@@ -193,61 +236,22 @@ func (te *Environment) Start() (*rest.Config, error) {
 			}
 		}
 	} else {
-		if te.ControlPlane.APIServer == nil {
-			te.ControlPlane.APIServer = &integration.APIServer{Args: te.getAPIServerFlags()}
-		}
-		if te.ControlPlane.Etcd == nil {
-			te.ControlPlane.Etcd = &integration.Etcd{}
-		}
-
-		if os.Getenv(envAttachOutput) == "true" {
-			te.AttachControlPlaneOutput = true
-		}
-		if te.ControlPlane.APIServer.Out == nil && te.AttachControlPlaneOutput {
-			te.ControlPlane.APIServer.Out = os.Stdout
-		}
-		if te.ControlPlane.APIServer.Err == nil && te.AttachControlPlaneOutput {
-			te.ControlPlane.APIServer.Err = os.Stderr
-		}
-		if te.ControlPlane.Etcd.Out == nil && te.AttachControlPlaneOutput {
-			te.ControlPlane.Etcd.Out = os.Stdout
-		}
-		if te.ControlPlane.Etcd.Err == nil && te.AttachControlPlaneOutput {
-			te.ControlPlane.Etcd.Err = os.Stderr
+		if err := te.configureControlPlane(); err != nil {
+			return nil, err
 		}
 
-		if os.Getenv(envKubeAPIServerBin) == "" {
-			te.ControlPlane.APIServer.Path = defaultAssetPath("kube-apiserver")
-		}
-		if os.Getenv(envEtcdBin) == "" {
-			te.ControlPlane.Etcd.Path = defaultAssetPath("etcd")
-		}
-		if os.Getenv(envKubectlBin) == "" {
-			// we can't just set the path manually (it's behind a function), so set the environment variable instead
-			if err := os.Setenv(envKubectlBin, defaultAssetPath("kubectl")); err != nil {
+		if te.shareControlPlane() {
+			if err := te.startSharedControlPlane(); err != nil {
+				return nil, err
+			}
+		} else {
+			log.V(1).Info("starting control plane", "api server flags", te.ControlPlane.APIServer.Args)
+			if err := te.startControlPlane(); err != nil {
 				return nil, err
 			}
-		}
-
-		if err := te.defaultTimeouts(); err != nil {
-			return nil, fmt.Errorf("failed to default controlplane timeouts: %w", err)
-		}
-		te.ControlPlane.Etcd.StartTimeout = te.ControlPlaneStartTimeout
-		te.ControlPlane.Etcd.StopTimeout = te.ControlPlaneStopTimeout
-		te.ControlPlane.APIServer.StartTimeout = te.ControlPlaneStartTimeout
-		te.ControlPlane.APIServer.StopTimeout = te.ControlPlaneStopTimeout
-
-		log.V(1).Info("starting control plane", "api server flags", te.ControlPlane.APIServer.Args)
-		if err := te.startControlPlane(); err != nil {
-			return nil, err
-		}
 
-		// Create the *rest.Config for creating new clients
-		te.Config = &rest.Config{
-			Host: te.ControlPlane.APIURL().Host,
-			// gotta go fast during tests -- we don't really care about overwhelming our test API server
-			QPS:   1000.0,
-			Burst: 2000.0,
+			// Create the *rest.Config for creating new clients
+			te.Config = newAdminConfig(te.ControlPlane.APIURL().Host)
 		}
 	}
 
@@ -267,6 +271,94 @@ func (te *Environment) Start() (*rest.Config, error) {
 	return te.Config, err
 }
 
+// AddUser provisions a user belonging to the given groups in the control
+// plane started by this Environment, and returns a *rest.Config authenticating
+// as that user through a client certificate.
+//
+// Unlike Config, which has unrestricted access, requests made with the returned
+// config are authorized with RBAC, so tests can create (Cluster)Roles and
+// bindings for the user and check which operations are allowed or forbidden.
+// Adding the user to the "system:masters" group grants it every permission.
+//
+// AddUser must be called after Start, and is not supported when using an
+// existing cluster.
+func (te *Environment) AddUser(name string, groups []string) (*rest.Config, error) {
+	if te.useExistingCluster() {
+		return nil, fmt.Errorf("unable to add user %q: adding users is not supported with an existing cluster", name)
+	}
+	if te.shareControlPlane() && te.ControlPlane.APIServer != nil && te.ControlPlane.APIServer.PID() == 0 {
+		return nil, fmt.Errorf("unable to add user %q: only the Environment that launched a shared control plane can add users", name)
+	}
+	cfg, err := te.ControlPlane.AddUser(name, groups)
+	if err != nil {
+		return nil, err
+	}
+	// gotta go fast during tests, same as the admin config
+	cfg.QPS = 1000.0
+	cfg.Burst = 2000.0
+	return cfg, nil
+}
+
+// configureControlPlane defaults the binaries, output and timeouts of the
+// control plane processes.
+func (te *Environment) configureControlPlane() error {
+	if te.ControlPlane.APIServer == nil {
+		te.ControlPlane.APIServer = &integration.APIServer{Args: te.getAPIServerFlags()}
+	}
+	if te.ControlPlane.Etcd == nil {
+		te.ControlPlane.Etcd = &integration.Etcd{}
+	}
+
+	if os.Getenv(envAttachOutput) == "true" {
+		te.AttachControlPlaneOutput = true
+	}
+	if te.ControlPlane.APIServer.Out == nil && te.AttachControlPlaneOutput {
+		te.ControlPlane.APIServer.Out = os.Stdout
+	}
+	if te.ControlPlane.APIServer.Err == nil && te.AttachControlPlaneOutput {
+		te.ControlPlane.APIServer.Err = os.Stderr
+	}
+	if te.ControlPlane.Etcd.Out == nil && te.AttachControlPlaneOutput {
+		te.ControlPlane.Etcd.Out = os.Stdout
+	}
+	if te.ControlPlane.Etcd.Err == nil && te.AttachControlPlaneOutput {
+		te.ControlPlane.Etcd.Err = os.Stderr
+	}
+
+	if os.Getenv(envKubeAPIServerBin) == "" {
+		te.ControlPlane.APIServer.Path = te.defaultAssetPath("kube-apiserver")
+	}
+	if os.Getenv(envEtcdBin) == "" {
+		te.ControlPlane.Etcd.Path = te.defaultAssetPath("etcd")
+	}
+	if os.Getenv(envKubectlBin) == "" {
+		// we can't just set the path manually (it's behind a function), so set the environment variable instead
+		if err := os.Setenv(envKubectlBin, te.defaultAssetPath("kubectl")); err != nil {
+			return err
+		}
+	}
+
+	if err := te.defaultTimeouts(); err != nil {
+		return fmt.Errorf("failed to default controlplane timeouts: %w", err)
+	}
+	te.ControlPlane.Etcd.StartTimeout = te.ControlPlaneStartTimeout
+	te.ControlPlane.Etcd.StopTimeout = te.ControlPlaneStopTimeout
+	te.ControlPlane.APIServer.StartTimeout = te.ControlPlaneStartTimeout
+	te.ControlPlane.APIServer.StopTimeout = te.ControlPlaneStopTimeout
+	return nil
+}
+
+// newAdminConfig returns a *rest.Config with unrestricted access to the API
+// server listening insecurely on the given host.
+func newAdminConfig(host string) *rest.Config {
+	return &rest.Config{
+		Host: host,
+		// gotta go fast during tests -- we don't really care about overwhelming our test API server
+		QPS:   1000.0,
+		Burst: 2000.0,
+	}
+}
+
 func (te *Environment) startControlPlane() error {
 	numTries, maxRetries := 0, 5
 	var err error
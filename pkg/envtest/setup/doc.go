@@ -0,0 +1,29 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package setup manages the control plane binaries used by envtest.
+//
+// Binaries are kept in a Store, a local directory holding one subdirectory
+// per Kubernetes version and platform.  Versions are requested with a
+// Selector (e.g. "1.19" for the latest installed 1.19 patch release), and can
+// be installed into the store either from a Remote, which downloads the
+// kubebuilder-tools archives, or from a local archive for offline use.
+// Archives are verified against a checksum before being installed.
+//
+// The directory returned for a version can be passed to
+// envtest.Environment as its BinaryAssetsDirectory.  The setup-envtest tool
+// in this repository wraps this package for use from the command line.
+package setup
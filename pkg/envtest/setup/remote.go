@@ -0,0 +1,180 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package setup
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	defaultRemoteServer = "https://storage.googleapis.com"
+	defaultRemoteBucket = "kubebuilder-tools"
+	archivePrefix       = "kubebuilder-tools-"
+	archiveSuffix       = ".tar.gz"
+)
+
+// Remote downloads the kubebuilder-tools archives from a Google Cloud Storage bucket.
+type Remote struct {
+	// Server is the base URL of the storage server.
+	// Defaults to https://storage.googleapis.com.
+	Server string
+
+	// Bucket is the bucket holding the archives.  Defaults to kubebuilder-tools.
+	Bucket string
+
+	// Client is the HTTP client used to talk to the server.  Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Archive is an archive of the binaries of a version for a platform.
+type Archive struct {
+	Version  Version
+	Platform Platform
+
+	// Name is the name of the archive in the bucket.
+	Name string
+
+	// Checksum is the checksum the storage server reports for the archive.
+	Checksum Checksum
+}
+
+// parseArchiveName parses the version and platform out of an archive name.
+func parseArchiveName(name string) (Version, Platform, bool) {
+	if !strings.HasPrefix(name, archivePrefix) || !strings.HasSuffix(name, archiveSuffix) {
+		return Version{}, Platform{}, false
+	}
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(name, archivePrefix), archiveSuffix), "-")
+	if len(parts) != 3 {
+		return Version{}, Platform{}, false
+	}
+	v, err := ParseVersion(parts[0])
+	if err != nil {
+		return Version{}, Platform{}, false
+	}
+	return v, Platform{OS: parts[1], Arch: parts[2]}, true
+}
+
+func (r *Remote) server() string {
+	if r.Server == "" {
+		return defaultRemoteServer
+	}
+	return strings.TrimSuffix(r.Server, "/")
+}
+
+func (r *Remote) bucket() string {
+	if r.Bucket == "" {
+		return defaultRemoteBucket
+	}
+	return r.Bucket
+}
+
+func (r *Remote) client() *http.Client {
+	if r.Client == nil {
+		return http.DefaultClient
+	}
+	return r.Client
+}
+
+// objectList is the subset of the Cloud Storage JSON API's list response we use.
+type objectList struct {
+	Items []struct {
+		Name    string `json:"name"`
+		MD5Hash string `json:"md5Hash"`
+	} `json:"items"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// List returns the archives available for the given platform.
+func (r *Remote) List(ctx context.Context, p Platform) ([]Archive, error) {
+	var archives []Archive
+	pageToken := ""
+	for {
+		query := url.Values{"prefix": []string{archivePrefix}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		listURL := fmt.Sprintf("%s/storage/v1/b/%s/o?%s", r.server(), url.PathEscape(r.bucket()), query.Encode())
+
+		var list objectList
+		if err := r.getJSON(ctx, listURL, &list); err != nil {
+			return nil, fmt.Errorf("unable to list archives: %w", err)
+		}
+		for _, item := range list.Items {
+			v, itemPlatform, ok := parseArchiveName(item.Name)
+			if !ok || itemPlatform != p {
+				continue
+			}
+			archive := Archive{Version: v, Platform: itemPlatform, Name: item.Name}
+			if item.MD5Hash != "" {
+				// the JSON API reports base64-encoded hashes
+				sum, err := base64.StdEncoding.DecodeString(item.MD5Hash)
+				if err != nil {
+					return nil, fmt.Errorf("invalid md5 hash for archive %s: %w", item.Name, err)
+				}
+				archive.Checksum = Checksum{Algorithm: "md5", Value: hex.EncodeToString(sum)}
+			}
+			archives = append(archives, archive)
+		}
+
+		if list.NextPageToken == "" {
+			return archives, nil
+		}
+		pageToken = list.NextPageToken
+	}
+}
+
+// Fetch downloads the given archive.  The caller must close the returned reader.
+func (r *Remote) Fetch(ctx context.Context, archive Archive) (io.ReadCloser, error) {
+	resp, err := r.get(ctx, fmt.Sprintf("%s/%s/%s", r.server(), url.PathEscape(r.bucket()), url.PathEscape(archive.Name)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to download archive %s: %w", archive.Name, err)
+	}
+	return resp.Body, nil
+}
+
+func (r *Remote) getJSON(ctx context.Context, url string, into interface{}) error {
+	resp, err := r.get(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(into)
+}
+
+func (r *Remote) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.client().Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: unexpected status %s", url, resp.Status)
+	}
+	return resp, nil
+}
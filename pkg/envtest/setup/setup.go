@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package setup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Options configure how versions are looked up and installed.
+type Options struct {
+	// Store is the store binaries are looked up in and installed to.
+	// Defaults to a store in DefaultStoreRoot.
+	Store *Store
+
+	// Remote is where versions that aren't installed are downloaded from.
+	// Defaults to the kubebuilder-tools bucket.
+	Remote *Remote
+
+	// Platform is the platform to use binaries for.  Defaults to the current platform.
+	Platform Platform
+
+	// InstalledOnly disables downloads, so that only installed versions are used.
+	InstalledOnly bool
+}
+
+func (o *Options) defaults() error {
+	if o.Store == nil {
+		root, err := DefaultStoreRoot()
+		if err != nil {
+			return err
+		}
+		o.Store = &Store{Root: root}
+	}
+	if o.Remote == nil {
+		o.Remote = &Remote{}
+	}
+	if o.Platform == (Platform{}) {
+		o.Platform = CurrentPlatform()
+	}
+	return nil
+}
+
+// Use returns the newest installed version selected by sel and the directory
+// holding its binaries.  If no installed version matches, the newest version
+// selected by sel is downloaded, verified against the checksum published for
+// it and installed, unless opts.InstalledOnly is set.
+func Use(ctx context.Context, sel Selector, opts Options) (Version, string, error) {
+	if err := opts.defaults(); err != nil {
+		return Version{}, "", err
+	}
+
+	v, dir, err := opts.Store.Resolve(sel, opts.Platform)
+	if err == nil || opts.InstalledOnly || !errors.Is(err, ErrNotInstalled) {
+		return v, dir, err
+	}
+
+	archives, err := opts.Remote.List(ctx, opts.Platform)
+	if err != nil {
+		return Version{}, "", err
+	}
+	var (
+		latest Archive
+		found  bool
+	)
+	for _, archive := range archives {
+		if sel.Matches(archive.Version) && (!found || archive.Version.NewerThan(latest.Version)) {
+			latest = archive
+			found = true
+		}
+	}
+	if !found {
+		return Version{}, "", fmt.Errorf("no version %s available for %s", sel, opts.Platform)
+	}
+	if latest.Checksum.Value == "" {
+		return Version{}, "", fmt.Errorf("no checksum published for archive %s, refusing to install it", latest.Name)
+	}
+
+	body, err := opts.Remote.Fetch(ctx, latest)
+	if err != nil {
+		return Version{}, "", err
+	}
+	defer body.Close()
+
+	dir, err = opts.Store.Add(latest.Version, opts.Platform, body, latest.Checksum)
+	return latest.Version, dir, err
+}
+
+// InstallFromFile installs the given version from a local kubebuilder-tools
+// archive, for environments without network access.  The archive must match
+// the given checksum, unless it is empty.
+func InstallFromFile(v Version, path string, sum Checksum, opts Options) (string, error) {
+	if err := opts.defaults(); err != nil {
+		return "", err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return opts.Store.Add(v, opts.Platform, f, sum)
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package setup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotInstalled is returned when no installed version matches a selector.
+var ErrNotInstalled = errors.New("no matching version installed")
+
+// Binaries are the names of the binaries a version of the control plane consists of.
+var Binaries = []string{"etcd", "kube-apiserver", "kubectl"}
+
+// Checksum is the expected checksum of an archive.
+type Checksum struct {
+	// Algorithm is the hash algorithm, either "sha256" or "md5".
+	Algorithm string
+
+	// Value is the hex-encoded hash of the archive.
+	Value string
+}
+
+func (c Checksum) newHash() (hash.Hash, error) {
+	switch strings.ToLower(c.Algorithm) {
+	case "sha256":
+		return sha256.New(), nil
+	case "md5":
+		return md5.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported checksum algorithm %q", c.Algorithm)
+	}
+}
+
+// DefaultStoreRoot returns the directory binaries are stored in by default,
+// a kubebuilder-envtest directory in the user's cache directory.
+func DefaultStoreRoot() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to determine the user cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "kubebuilder-envtest"), nil
+}
+
+// Store is a local directory of control plane binaries, with one
+// subdirectory per version and platform.
+type Store struct {
+	// Root is the directory the binaries are stored in.
+	Root string
+}
+
+// Path returns the directory the binaries of the given version and platform
+// are, or would be, stored in.
+func (s *Store) Path(v Version, p Platform) string {
+	return filepath.Join(s.Root, "k8s", v.String()+"-"+p.String())
+}
+
+// List returns the versions installed for the given platform.
+func (s *Store) List(p Platform) ([]Version, error) {
+	entries, err := ioutil.ReadDir(filepath.Join(s.Root, "k8s"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := "-" + p.String()
+	var versions []Version
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasSuffix(entry.Name(), suffix) {
+			continue
+		}
+		v, err := ParseVersion(strings.TrimSuffix(entry.Name(), suffix))
+		if err != nil {
+			// not ours, or a partial install
+			continue
+		}
+		if s.complete(v, p) {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+
+// complete returns true if all the binaries of the given version are installed.
+func (s *Store) complete(v Version, p Platform) bool {
+	for _, bin := range Binaries {
+		if _, err := os.Stat(filepath.Join(s.Path(v, p), bin)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Resolve returns the newest installed version selected by sel, along with
+// the directory holding its binaries.  It returns an error wrapping
+// ErrNotInstalled if there is no such version.
+func (s *Store) Resolve(sel Selector, p Platform) (Version, string, error) {
+	versions, err := s.List(p)
+	if err != nil {
+		return Version{}, "", err
+	}
+	v, found := sel.Latest(versions)
+	if !found {
+		return Version{}, "", fmt.Errorf("version %s for %s: %w", sel, p, ErrNotInstalled)
+	}
+	return v, s.Path(v, p), nil
+}
+
+// Add installs the binaries found in the given gzipped tar archive as the
+// given version, replacing any previous install of it, and returns the
+// directory holding them.  Nothing is installed unless the archive matches
+// the given checksum; an empty checksum skips the verification.
+func (s *Store) Add(v Version, p Platform, archive io.Reader, sum Checksum) (string, error) {
+	var hasher hash.Hash
+	if sum.Value != "" {
+		var err error
+		if hasher, err = sum.newHash(); err != nil {
+			return "", err
+		}
+		archive = io.TeeReader(archive, hasher)
+	}
+
+	if err := os.MkdirAll(filepath.Join(s.Root, "k8s"), 0755); err != nil {
+		return "", err
+	}
+	// extract next to the final directory, so that it can be moved into place atomically
+	tmpDir, err := ioutil.TempDir(filepath.Join(s.Root, "k8s"), ".install-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := extractBinaries(archive, tmpDir); err != nil {
+		return "", fmt.Errorf("unable to extract archive for version %s: %w", v, err)
+	}
+	if hasher != nil {
+		// make sure the checksum covers the whole archive, and not just the part tar read
+		if _, err := io.Copy(ioutil.Discard, archive); err != nil {
+			return "", err
+		}
+		if actual := hex.EncodeToString(hasher.Sum(nil)); !strings.EqualFold(actual, sum.Value) {
+			return "", fmt.Errorf("checksum mismatch for version %s: expected %s %s, got %s", v, sum.Algorithm, sum.Value, actual)
+		}
+	}
+	for _, bin := range Binaries {
+		if _, err := os.Stat(filepath.Join(tmpDir, bin)); err != nil {
+			return "", fmt.Errorf("archive for version %s does not contain %s", v, bin)
+		}
+	}
+
+	dir := s.Path(v, p)
+	if err := os.RemoveAll(dir); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpDir, dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// Remove uninstalls the given version.
+func (s *Store) Remove(v Version, p Platform) error {
+	return os.RemoveAll(s.Path(v, p))
+}
+
+// extractBinaries writes the control plane binaries found anywhere in the
+// given gzipped tar archive to dir.
+func extractBinaries(archive io.Reader, dir string) error {
+	gzr, err := gzip.NewReader(archive)
+	if err != nil {
+		return err
+	}
+	defer gzr.Close()
+
+	wanted := map[string]bool{}
+	for _, bin := range Binaries {
+		wanted[bin] = true
+	}
+
+	tr := tar.NewReader(gzr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.Base(hdr.Name)
+		if hdr.Typeflag != tar.TypeReg || !wanted[name] {
+			continue
+		}
+		if err := writeExecutable(filepath.Join(dir, name), tr); err != nil {
+			return err
+		}
+	}
+}
+
+func writeExecutable(path string, contents io.Reader) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, contents); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package setup_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-runtime/pkg/envtest/setup"
+)
+
+// makeArchive returns a kubebuilder-tools like archive holding the given binaries.
+func makeArchive(binaries ...string) []byte {
+	buf := &bytes.Buffer{}
+	gzw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gzw)
+	Expect(tw.WriteHeader(&tar.Header{Name: "kubebuilder/bin/", Typeflag: tar.TypeDir, Mode: 0755})).To(Succeed())
+	for _, bin := range binaries {
+		contents := []byte("#!/bin/sh\necho " + bin)
+		Expect(tw.WriteHeader(&tar.Header{
+			Name:     "kubebuilder/bin/" + bin,
+			Typeflag: tar.TypeReg,
+			Mode:     0755,
+			Size:     int64(len(contents)),
+		})).To(Succeed())
+		_, err := tw.Write(contents)
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(tw.Close()).To(Succeed())
+	Expect(gzw.Close()).To(Succeed())
+	return buf.Bytes()
+}
+
+func sha256Of(data []byte) setup.Checksum {
+	sum := sha256.Sum256(data)
+	return setup.Checksum{Algorithm: "sha256", Value: hex.EncodeToString(sum[:])}
+}
+
+var _ = Describe("Store", func() {
+	var (
+		store    *setup.Store
+		platform = setup.Platform{OS: "linux", Arch: "amd64"}
+		archive  []byte
+	)
+
+	BeforeEach(func() {
+		root, err := ioutil.TempDir("", "envtest-store-")
+		Expect(err).NotTo(HaveOccurred())
+		store = &setup.Store{Root: root}
+		archive = makeArchive(setup.Binaries...)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(store.Root)).To(Succeed())
+	})
+
+	It("should install and resolve versions", func() {
+		for _, v := range []setup.Version{{1, 18, 9}, {1, 19, 2}} {
+			dir, err := store.Add(v, platform, bytes.NewReader(archive), sha256Of(archive))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dir).To(Equal(store.Path(v, platform)))
+			for _, bin := range setup.Binaries {
+				info, err := os.Stat(filepath.Join(dir, bin))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Mode() & 0111).NotTo(BeZero())
+			}
+		}
+
+		Expect(store.List(platform)).To(ConsistOf(setup.Version{1, 18, 9}, setup.Version{1, 19, 2}))
+		Expect(store.List(setup.Platform{OS: "darwin", Arch: "amd64"})).To(BeEmpty())
+
+		sel, err := setup.ParseSelector("1.18")
+		Expect(err).NotTo(HaveOccurred())
+		v, dir, err := store.Resolve(sel, platform)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(setup.Version{1, 18, 9}))
+		Expect(dir).To(Equal(store.Path(v, platform)))
+	})
+
+	It("should report versions that aren't installed", func() {
+		sel, err := setup.ParseSelector("1.19")
+		Expect(err).NotTo(HaveOccurred())
+		_, _, err = store.Resolve(sel, platform)
+		Expect(errors.Is(err, setup.ErrNotInstalled)).To(BeTrue())
+	})
+
+	It("should refuse archives not matching their checksum", func() {
+		v := setup.Version{1, 19, 2}
+		_, err := store.Add(v, platform, bytes.NewReader(archive), setup.Checksum{Algorithm: "sha256", Value: "abcd"})
+		Expect(err).To(MatchError(ContainSubstring("checksum mismatch")))
+		Expect(store.List(platform)).To(BeEmpty())
+		_, err = os.Stat(store.Path(v, platform))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("should refuse incomplete archives", func() {
+		partial := makeArchive("etcd")
+		_, err := store.Add(setup.Version{1, 19, 2}, platform, bytes.NewReader(partial), sha256Of(partial))
+		Expect(err).To(MatchError(ContainSubstring("does not contain kube-apiserver")))
+	})
+
+	It("should remove versions", func() {
+		v := setup.Version{1, 19, 2}
+		_, err := store.Add(v, platform, bytes.NewReader(archive), setup.Checksum{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.Remove(v, platform)).To(Succeed())
+		Expect(store.List(platform)).To(BeEmpty())
+	})
+
+	It("should install from local archives", func() {
+		f, err := ioutil.TempFile("", "kubebuilder-tools-")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.Remove(f.Name())
+		_, err = f.Write(archive)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Close()).To(Succeed())
+
+		v := setup.Version{1, 19, 2}
+		dir, err := setup.InstallFromFile(v, f.Name(), sha256Of(archive), setup.Options{Store: store, Platform: platform})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dir).To(Equal(store.Path(v, platform)))
+	})
+
+	Describe("Use", func() {
+		var (
+			server    *httptest.Server
+			downloads int
+			md5Hash   string
+		)
+
+		BeforeEach(func() {
+			sum := md5.Sum(archive)
+			md5Hash = base64.StdEncoding.EncodeToString(sum[:])
+			downloads = 0
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/storage/v1/b/kubebuilder-tools/o", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.URL.Query().Get("prefix")).To(Equal("kubebuilder-tools-"))
+				fmt.Fprintf(w, `{"items": [
+					{"name": "kubebuilder-tools-1.19.2-linux-amd64.tar.gz", "md5Hash": %q},
+					{"name": "kubebuilder-tools-1.19.0-linux-amd64.tar.gz", "md5Hash": %q},
+					{"name": "kubebuilder-tools-1.20.0-darwin-amd64.tar.gz", "md5Hash": %q}
+				]}`, md5Hash, md5Hash, md5Hash)
+			})
+			mux.HandleFunc("/kubebuilder-tools/kubebuilder-tools-1.19.2-linux-amd64.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+				downloads++
+				_, _ = w.Write(archive)
+			})
+			server = httptest.NewServer(mux)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		use := func(selector string, installedOnly bool) (setup.Version, string, error) {
+			sel, err := setup.ParseSelector(selector)
+			Expect(err).NotTo(HaveOccurred())
+			return setup.Use(context.Background(), sel, setup.Options{
+				Store:         store,
+				Remote:        &setup.Remote{Server: server.URL},
+				Platform:      platform,
+				InstalledOnly: installedOnly,
+			})
+		}
+
+		It("should download the newest matching version when none is installed", func() {
+			v, dir, err := use("1.19", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(Equal(setup.Version{1, 19, 2}))
+			Expect(dir).To(Equal(store.Path(v, platform)))
+			Expect(downloads).To(Equal(1))
+
+			By("reusing it afterwards")
+			_, _, err = use("1.19", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(downloads).To(Equal(1))
+		})
+
+		It("should not download when restricted to installed versions", func() {
+			_, _, err := use("1.19", true)
+			Expect(errors.Is(err, setup.ErrNotInstalled)).To(BeTrue())
+			Expect(downloads).To(BeZero())
+		})
+
+		It("should fail when no version is available for the platform", func() {
+			_, _, err := use("1.20", false)
+			Expect(err).To(MatchError(ContainSubstring("no version 1.20 available for linux-amd64")))
+		})
+
+		It("should refuse downloads not matching the published checksum", func() {
+			archive = append(archive[:len(archive):len(archive)], 0)
+			_, _, err := use("1.19", false)
+			Expect(err).To(MatchError(ContainSubstring("checksum mismatch")))
+			Expect(store.List(platform)).To(BeEmpty())
+		})
+	})
+})
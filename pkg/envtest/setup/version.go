@@ -0,0 +1,159 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package setup
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Version is a Kubernetes release version, such as 1.19.2.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+// ParseVersion parses a version of the form "1.19.2", optionally prefixed with "v".
+func ParseVersion(s string) (Version, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("invalid version %q, expected major.minor.patch", s)
+	}
+	nums, err := parseNumbers(parts)
+	if err != nil {
+		return Version{}, fmt.Errorf("invalid version %q: %w", s, err)
+	}
+	return Version{Major: nums[0], Minor: nums[1], Patch: nums[2]}, nil
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// NewerThan returns true if v is a later release than other.
+func (v Version) NewerThan(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor > other.Minor
+	}
+	return v.Patch > other.Patch
+}
+
+// Selector selects the versions to use.  An empty part of the selector
+// matches any value.
+type Selector struct {
+	// Major, Minor and Patch are the parts a version must have to match, if set
+	Major, Minor, Patch *int
+}
+
+// ParseSelector parses a version selector.  It may be a complete version
+// ("1.19.2"), a minor release ("1.19", matching any of its patch releases), a
+// major release ("1") or empty or "latest" (matching any version).
+func ParseSelector(s string) (Selector, error) {
+	s = strings.TrimPrefix(s, "v")
+	if s == "" || s == "latest" {
+		return Selector{}, nil
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return Selector{}, fmt.Errorf("invalid version selector %q", s)
+	}
+	nums, err := parseNumbers(parts)
+	if err != nil {
+		return Selector{}, fmt.Errorf("invalid version selector %q: %w", s, err)
+	}
+
+	var sel Selector
+	for i, dst := range []**int{&sel.Major, &sel.Minor, &sel.Patch} {
+		if i < len(nums) {
+			n := nums[i]
+			*dst = &n
+		}
+	}
+	return sel, nil
+}
+
+// Matches returns true if the given version is selected.
+func (s Selector) Matches(v Version) bool {
+	return (s.Major == nil || *s.Major == v.Major) &&
+		(s.Minor == nil || *s.Minor == v.Minor) &&
+		(s.Patch == nil || *s.Patch == v.Patch)
+}
+
+// Exact returns the version selected by s if it only selects a single version.
+func (s Selector) Exact() (Version, bool) {
+	if s.Major == nil || s.Minor == nil || s.Patch == nil {
+		return Version{}, false
+	}
+	return Version{Major: *s.Major, Minor: *s.Minor, Patch: *s.Patch}, true
+}
+
+func (s Selector) String() string {
+	var parts []string
+	for _, n := range []*int{s.Major, s.Minor, s.Patch} {
+		if n == nil {
+			break
+		}
+		parts = append(parts, strconv.Itoa(*n))
+	}
+	if len(parts) == 0 {
+		return "latest"
+	}
+	return strings.Join(parts, ".")
+}
+
+// Latest returns the newest of the given versions that is selected by s.
+func (s Selector) Latest(versions []Version) (Version, bool) {
+	var latest Version
+	found := false
+	for _, v := range versions {
+		if s.Matches(v) && (!found || v.NewerThan(latest)) {
+			latest = v
+			found = true
+		}
+	}
+	return latest, found
+}
+
+// Platform is an operating system and architecture pair, as named by Go.
+type Platform struct {
+	OS, Arch string
+}
+
+// CurrentPlatform returns the platform this binary runs on.
+func CurrentPlatform() Platform {
+	return Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+}
+
+func (p Platform) String() string {
+	return p.OS + "-" + p.Arch
+}
+
+func parseNumbers(parts []string) ([]int, error) {
+	nums := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%q is not a version number", part)
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package setup_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-runtime/pkg/envtest/setup"
+)
+
+var _ = Describe("Versions", func() {
+	It("should parse complete versions", func() {
+		v, err := setup.ParseVersion("v1.19.2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(setup.Version{Major: 1, Minor: 19, Patch: 2}))
+		Expect(v.String()).To(Equal("1.19.2"))
+	})
+
+	It("should refuse incomplete or invalid versions", func() {
+		for _, s := range []string{"1.19", "1.19.x", "", "1.19.2.3"} {
+			_, err := setup.ParseVersion(s)
+			Expect(err).To(HaveOccurred(), s)
+		}
+	})
+
+	It("should select matching versions", func() {
+		versions := []setup.Version{{1, 18, 9}, {1, 19, 2}, {1, 19, 0}, {1, 16, 4}}
+
+		for sel, expected := range map[string]setup.Version{
+			"":       {1, 19, 2},
+			"latest": {1, 19, 2},
+			"1":      {1, 19, 2},
+			"1.18":   {1, 18, 9},
+			"1.19.0": {1, 19, 0},
+		} {
+			s, err := setup.ParseSelector(sel)
+			Expect(err).NotTo(HaveOccurred())
+			latest, found := s.Latest(versions)
+			Expect(found).To(BeTrue(), sel)
+			Expect(latest).To(Equal(expected), sel)
+		}
+
+		s, err := setup.ParseSelector("1.17")
+		Expect(err).NotTo(HaveOccurred())
+		_, found := s.Latest(versions)
+		Expect(found).To(BeFalse())
+	})
+
+	It("should report exact selectors", func() {
+		s, err := setup.ParseSelector("1.19.2")
+		Expect(err).NotTo(HaveOccurred())
+		v, exact := s.Exact()
+		Expect(exact).To(BeTrue())
+		Expect(v).To(Equal(setup.Version{Major: 1, Minor: 19, Patch: 2}))
+
+		s, err = setup.ParseSelector("1.19")
+		Expect(err).NotTo(HaveOccurred())
+		_, exact = s.Exact()
+		Expect(exact).To(BeFalse())
+		Expect(s.String()).To(Equal("1.19"))
+	})
+})
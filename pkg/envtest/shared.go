@@ -0,0 +1,343 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	sharedLockFile       = "lock"
+	sharedStateFile      = "state.json"
+	sharedKubeconfigFile = "kubeconfig"
+)
+
+// sharedControlPlane describes a control plane shared between test processes,
+// as published in the shared directory.
+type sharedControlPlane struct {
+	// APIServerURL is the insecure URL of the API server.
+	APIServerURL string `json:"apiServerURL"`
+
+	// APIServerPID and EtcdPID are the process IDs of the control plane.
+	APIServerPID int `json:"apiServerPID"`
+	EtcdPID      int `json:"etcdPID"`
+
+	// APIServerPath and EtcdPath are the binaries of the control plane, used
+	// to make sure we don't stop unrelated processes that reused a PID.
+	APIServerPath string `json:"apiServerPath"`
+	EtcdPath      string `json:"etcdPath"`
+
+	// Dirs are the directories created for the control plane, which are
+	// removed when tearing it down.
+	Dirs []string `json:"dirs,omitempty"`
+
+	// Users are the process IDs of the test processes using the control
+	// plane, once per Environment.
+	Users []int `json:"users"`
+}
+
+func (te *Environment) shareControlPlane() bool {
+	if te.ShareControlPlane == nil {
+		return strings.ToLower(os.Getenv(envShareControlPlane)) == "true"
+	}
+	return *te.ShareControlPlane
+}
+
+func (te *Environment) sharedControlPlaneDir() string {
+	if te.SharedControlPlaneDir != "" {
+		return te.SharedControlPlaneDir
+	}
+	if dir := os.Getenv(envSharedDir); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "kubebuilder-envtest-shared")
+}
+
+// startSharedControlPlane attaches to the control plane published in the
+// shared directory, launching one if there's none (or only a stale one), and
+// creates the Namespace of this Environment.
+func (te *Environment) startSharedControlPlane() error {
+	dir := te.sharedControlPlaneDir()
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+	unlock, err := lockDir(filepath.Join(dir, sharedLockFile))
+	if err != nil {
+		return fmt.Errorf("unable to lock shared control plane directory %s: %w", dir, err)
+	}
+	defer unlock()
+
+	shared, err := readSharedControlPlane(dir)
+	if err != nil {
+		return err
+	}
+	if shared != nil && !shared.usable() {
+		log.Info("cleaning up stale shared control plane", "dir", dir)
+		if err := shared.tearDown(dir, te.ControlPlaneStopTimeout); err != nil {
+			return fmt.Errorf("unable to clean up stale shared control plane: %w", err)
+		}
+		shared = nil
+	}
+
+	if shared == nil {
+		shared, err = te.launchSharedControlPlane(dir)
+		if err != nil {
+			return err
+		}
+	} else {
+		log.V(1).Info("attaching to shared control plane", "url", shared.APIServerURL)
+	}
+
+	shared.Users = append(shared.Users, os.Getpid())
+	if err := writeSharedControlPlane(dir, shared); err != nil {
+		return err
+	}
+
+	apiURL, err := url.Parse(shared.APIServerURL)
+	if err != nil {
+		return err
+	}
+	te.Config = newAdminConfig(apiURL.Host)
+
+	return te.createNamespace()
+}
+
+// launchSharedControlPlane starts a control plane that outlives the current
+// process, and publishes a kubeconfig for it.
+func (te *Environment) launchSharedControlPlane(dir string) (*sharedControlPlane, error) {
+	apiServer, etcd := te.ControlPlane.APIServer, te.ControlPlane.Etcd
+	// only remove the directories we create
+	removeCertDir, removeDataDir := apiServer.CertDir == "", etcd.DataDir == ""
+
+	apiServer.Detached = true
+	etcd.Detached = true
+	log.V(1).Info("starting shared control plane", "api server flags", apiServer.Args)
+	if err := te.startControlPlane(); err != nil {
+		return nil, err
+	}
+
+	shared := &sharedControlPlane{
+		APIServerURL:  te.ControlPlane.APIURL().String(),
+		APIServerPID:  apiServer.PID(),
+		EtcdPID:       etcd.PID(),
+		APIServerPath: apiServer.Path,
+		EtcdPath:      etcd.Path,
+	}
+	if removeCertDir {
+		shared.Dirs = append(shared.Dirs, apiServer.CertDir)
+	}
+	if removeDataDir {
+		shared.Dirs = append(shared.Dirs, etcd.DataDir)
+	}
+
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters["envtest"] = &clientcmdapi.Cluster{Server: shared.APIServerURL}
+	kubeconfig.AuthInfos["envtest"] = &clientcmdapi.AuthInfo{}
+	kubeconfig.Contexts["envtest"] = &clientcmdapi.Context{Cluster: "envtest", AuthInfo: "envtest"}
+	kubeconfig.CurrentContext = "envtest"
+	if err := clientcmd.WriteToFile(*kubeconfig, filepath.Join(dir, sharedKubeconfigFile)); err != nil {
+		return nil, err
+	}
+
+	return shared, nil
+}
+
+// stopSharedControlPlane deletes the Namespace of this Environment and
+// detaches from the shared control plane, tearing it down if this was its
+// last user.
+func (te *Environment) stopSharedControlPlane() error {
+	if err := te.deleteNamespace(); err != nil {
+		return err
+	}
+
+	dir := te.sharedControlPlaneDir()
+	unlock, err := lockDir(filepath.Join(dir, sharedLockFile))
+	if err != nil {
+		return fmt.Errorf("unable to lock shared control plane directory %s: %w", dir, err)
+	}
+	defer unlock()
+
+	shared, err := readSharedControlPlane(dir)
+	if err != nil || shared == nil {
+		return err
+	}
+
+	for i, pid := range shared.Users {
+		if pid == os.Getpid() {
+			shared.Users = append(shared.Users[:i], shared.Users[i+1:]...)
+			break
+		}
+	}
+	shared.pruneUsers()
+	if len(shared.Users) > 0 {
+		return writeSharedControlPlane(dir, shared)
+	}
+
+	log.V(1).Info("tearing down shared control plane", "url", shared.APIServerURL)
+	return shared.tearDown(dir, te.ControlPlaneStopTimeout)
+}
+
+func (te *Environment) createNamespace() error {
+	c, err := client.New(te.Config, client.Options{})
+	if err != nil {
+		return err
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "envtest-"}}
+	if err := c.Create(context.TODO(), ns); err != nil {
+		return fmt.Errorf("unable to create namespace: %w", err)
+	}
+	te.Namespace = ns.Name
+	return nil
+}
+
+func (te *Environment) deleteNamespace() error {
+	if te.Namespace == "" {
+		return nil
+	}
+	c, err := client.New(te.Config, client.Options{})
+	if err != nil {
+		return err
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: te.Namespace}}
+	if err := c.Delete(context.TODO(), ns); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete namespace %s: %w", te.Namespace, err)
+	}
+	te.Namespace = ""
+	return nil
+}
+
+// pruneUsers forgets the users whose process has exited without detaching,
+// e.g. because it crashed.
+func (s *sharedControlPlane) pruneUsers() {
+	users := s.Users[:0]
+	for _, pid := range s.Users {
+		if processAlive(pid) {
+			users = append(users, pid)
+		}
+	}
+	s.Users = users
+}
+
+// usable checks that the shared control plane still has users, and that it
+// is up and healthy.
+func (s *sharedControlPlane) usable() bool {
+	s.pruneUsers()
+	if len(s.Users) == 0 {
+		return false
+	}
+	if !processAlive(s.APIServerPID) || !processAlive(s.EtcdPID) {
+		return false
+	}
+
+	healthURL, err := url.Parse(s.APIServerURL)
+	if err != nil {
+		return false
+	}
+	healthURL.Path = "/healthz"
+	httpClient := &http.Client{Timeout: 5 * time.Second}
+	resp, err := httpClient.Get(healthURL.String())
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// tearDown stops the processes of the shared control plane, and removes it
+// and its directories.
+func (s *sharedControlPlane) tearDown(dir string, timeout time.Duration) error {
+	if err := stopProcess(s.APIServerPID, s.APIServerPath, timeout); err != nil {
+		return err
+	}
+	if err := stopProcess(s.EtcdPID, s.EtcdPath, timeout); err != nil {
+		return err
+	}
+	for _, d := range s.Dirs {
+		if err := os.RemoveAll(d); err != nil {
+			return err
+		}
+	}
+	for _, f := range []string{sharedStateFile, sharedKubeconfigFile} {
+		if err := os.Remove(filepath.Join(dir, f)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// stopProcess terminates the given process, if it's still running the given
+// binary, and waits for it to exit.  It's killed if it does not exit in time.
+func stopProcess(pid int, path string, timeout time.Duration) error {
+	if !processAlive(pid) || !processRuns(pid, path) {
+		return nil
+	}
+	if err := terminateProcess(pid); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if !processAlive(pid) {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return killProcess(pid)
+}
+
+func readSharedControlPlane(dir string) (*sharedControlPlane, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, sharedStateFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	shared := &sharedControlPlane{}
+	if err := json.Unmarshal(data, shared); err != nil {
+		return nil, fmt.Errorf("unable to parse shared control plane state: %w", err)
+	}
+	return shared, nil
+}
+
+func writeSharedControlPlane(dir string, shared *sharedControlPlane) error {
+	data, err := json.Marshal(shared)
+	if err != nil {
+		return err
+	}
+	// write atomically, so readers never see a partial state
+	tmp := filepath.Join(dir, sharedStateFile+".tmp")
+	if err := ioutil.WriteFile(tmp, data, 0640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, sharedStateFile))
+}
@@ -0,0 +1,80 @@
+// +build !windows
+
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envtest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// lockDir takes an exclusive lock on the given file, creating it if needed.
+// The lock is released by the returned function, or when the process exits.
+func lockDir(lockPath string) (func(), error) {
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0640)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+// processRuns checks, where possible, that the given process runs the given
+// binary.
+func processRuns(pid int, path string) bool {
+	cmdline, err := ioutil.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		// no procfs, assume it does
+		return true
+	}
+	argv0 := string(bytes.SplitN(cmdline, []byte{0}, 2)[0])
+	return filepath.Base(argv0) == filepath.Base(path)
+}
+
+func terminateProcess(pid int) error {
+	return ignoreGone(syscall.Kill(pid, syscall.SIGTERM))
+}
+
+func killProcess(pid int) error {
+	return ignoreGone(syscall.Kill(pid, syscall.SIGKILL))
+}
+
+func ignoreGone(err error) error {
+	if err == syscall.ESRCH {
+		return nil
+	}
+	return err
+}
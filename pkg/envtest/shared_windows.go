@@ -0,0 +1,39 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envtest
+
+import "fmt"
+
+func lockDir(lockPath string) (func(), error) {
+	return nil, fmt.Errorf("sharing a control plane is not supported on windows")
+}
+
+func processAlive(pid int) bool {
+	return false
+}
+
+func processRuns(pid int, path string) bool {
+	return false
+}
+
+func terminateProcess(pid int) error {
+	return fmt.Errorf("sharing a control plane is not supported on windows")
+}
+
+func killProcess(pid int) error {
+	return fmt.Errorf("sharing a control plane is not supported on windows")
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package event
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// TypedCreateEvent is a CreateEvent for an object of type T.
+type TypedCreateEvent[T runtime.Object] struct {
+	// Object is the object from the event
+	Object T
+}
+
+// TypedUpdateEvent is an UpdateEvent for an object of type T.
+type TypedUpdateEvent[T runtime.Object] struct {
+	// ObjectOld is the object from the event, before the update
+	ObjectOld T
+
+	// ObjectNew is the object from the event, after the update
+	ObjectNew T
+}
+
+// TypedDeleteEvent is a DeleteEvent for an object of type T.
+type TypedDeleteEvent[T runtime.Object] struct {
+	// Object is the object from the event
+	Object T
+
+	// DeleteStateUnknown is true if the Delete event was missed but we identified the object
+	// as having been deleted.
+	DeleteStateUnknown bool
+}
+
+// TypedGenericEvent is a GenericEvent for an object of type T.
+type TypedGenericEvent[T runtime.Object] struct {
+	// Object is the object from the event
+	Object T
+}
+
+// ToTypedCreateEvent converts a CreateEvent to a TypedCreateEvent.  It returns false if
+// the object of the event is not of type T.
+func ToTypedCreateEvent[T runtime.Object](e CreateEvent) (TypedCreateEvent[T], bool) {
+	obj, ok := e.Object.(T)
+	return TypedCreateEvent[T]{Object: obj}, ok
+}
+
+// ToTypedUpdateEvent converts an UpdateEvent to a TypedUpdateEvent.  It returns false if
+// either object of the event is not of type T.
+func ToTypedUpdateEvent[T runtime.Object](e UpdateEvent) (TypedUpdateEvent[T], bool) {
+	oldObj, okOld := e.ObjectOld.(T)
+	newObj, okNew := e.ObjectNew.(T)
+	return TypedUpdateEvent[T]{ObjectOld: oldObj, ObjectNew: newObj}, okOld && okNew
+}
+
+// ToTypedDeleteEvent converts a DeleteEvent to a TypedDeleteEvent.  It returns false if
+// the object of the event is not of type T.
+func ToTypedDeleteEvent[T runtime.Object](e DeleteEvent) (TypedDeleteEvent[T], bool) {
+	obj, ok := e.Object.(T)
+	return TypedDeleteEvent[T]{Object: obj, DeleteStateUnknown: e.DeleteStateUnknown}, ok
+}
+
+// ToTypedGenericEvent converts a GenericEvent to a TypedGenericEvent.  It returns false if
+// the object of the event is not of type T.
+func ToTypedGenericEvent[T runtime.Object](e GenericEvent) (TypedGenericEvent[T], bool) {
+	obj, ok := e.Object.(T)
+	return TypedGenericEvent[T]{Object: obj}, ok
+}
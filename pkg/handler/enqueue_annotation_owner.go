@@ -0,0 +1,96 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+var _ EventHandler = &EnqueueRequestForAnnotationOwner{}
+var _ inject.Scheme = &EnqueueRequestForAnnotationOwner{}
+
+// EnqueueRequestForAnnotationOwner enqueues Requests for the owner of an object recorded in its
+// annotations by controllerutil.SetOwnerAnnotations.  Unlike EnqueueRequestForOwner, the owner
+// may be in another namespace than the object, or cluster-scoped.
+//
+// If a cluster-scoped Foo creates ConfigMaps in several namespaces, users may reconcile the Foo
+// in response to ConfigMap Events using:
+//
+// - a source.Kind Source with Type of ConfigMap.
+//
+// - a handler.EnqueueRequestForAnnotationOwner EventHandler with an OwnerType of Foo.
+type EnqueueRequestForAnnotationOwner struct {
+	// OwnerType is the type of the owner object to look for in annotations.  Only Group and Kind are compared.
+	OwnerType runtime.Object
+
+	// groupKind is the cached Group and Kind from OwnerType
+	groupKind schema.GroupKind
+}
+
+// Create implements EventHandler
+func (e *EnqueueRequestForAnnotationOwner) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.enqueueOwner(evt.Meta, q)
+}
+
+// Update implements EventHandler
+func (e *EnqueueRequestForAnnotationOwner) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.enqueueOwner(evt.MetaOld, q)
+	e.enqueueOwner(evt.MetaNew, q)
+}
+
+// Delete implements EventHandler
+func (e *EnqueueRequestForAnnotationOwner) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.enqueueOwner(evt.Meta, q)
+}
+
+// Generic implements EventHandler
+func (e *EnqueueRequestForAnnotationOwner) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.enqueueOwner(evt.Meta, q)
+}
+
+// enqueueOwner enqueues a Request for the owner of object, if it is of OwnerType.
+func (e *EnqueueRequestForAnnotationOwner) enqueueOwner(object metav1.Object, q workqueue.RateLimitingInterface) {
+	if object == nil {
+		return
+	}
+	owner, ok := controllerutil.GetOwnerAnnotations(object)
+	if !ok || owner.GroupKind() != e.groupKind {
+		return
+	}
+	q.Add(reconcile.Request{NamespacedName: owner.NamespacedName})
+}
+
+// InjectScheme is called by the Controller to provide a singleton scheme to the EnqueueRequestForAnnotationOwner.
+func (e *EnqueueRequestForAnnotationOwner) InjectScheme(s *runtime.Scheme) error {
+	gvk, err := apiutil.GVKForObject(e.OwnerType, s)
+	if err != nil {
+		log.Error(err, "Could not get the kind of OwnerType", "owner type", fmt.Sprintf("%T", e.OwnerType))
+		return err
+	}
+	e.groupKind = gvk.GroupKind()
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+var _ = Describe("EnqueueRequestForAnnotationOwner", func() {
+	var q workqueue.RateLimitingInterface
+	var instance *handler.EnqueueRequestForAnnotationOwner
+
+	BeforeEach(func() {
+		q = controllertest.Queue{Interface: workqueue.New()}
+		instance = &handler.EnqueueRequestForAnnotationOwner{OwnerType: &rbacv1.ClusterRole{}}
+		Expect(inject.SchemeInto(scheme.Scheme, instance)).To(BeTrue())
+	})
+
+	ownedBy := func(owner metav1.Object, name string) *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: name}}
+		Expect(controllerutil.SetOwnerAnnotations(owner, cm, scheme.Scheme)).To(Succeed())
+		return cm
+	}
+
+	It("should enqueue a Request for a cluster-scoped owner of a namespaced object", func() {
+		cm := ownedBy(&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}, "baz")
+		instance.Create(event.CreateEvent{Meta: cm, Object: cm}, q)
+		Expect(q.Len()).To(Equal(1))
+		i, _ := q.Get()
+		Expect(i).To(Equal(reconcile.Request{NamespacedName: types.NamespacedName{Name: "foo"}}))
+	})
+
+	It("should enqueue Requests for the owners of both objects of an UpdateEvent", func() {
+		oldCM := ownedBy(&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}, "baz")
+		newCM := ownedBy(&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "bar"}}, "baz")
+		instance.Update(event.UpdateEvent{MetaOld: oldCM, ObjectOld: oldCM, MetaNew: newCM, ObjectNew: newCM}, q)
+		Expect(q.Len()).To(Equal(2))
+	})
+
+	It("should enqueue a Request for an owner in another namespace", func() {
+		instance = &handler.EnqueueRequestForAnnotationOwner{OwnerType: &appsv1.Deployment{}}
+		Expect(inject.SchemeInto(scheme.Scheme, instance)).To(BeTrue())
+
+		cm := ownedBy(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "foo"}}, "baz")
+		instance.Delete(event.DeleteEvent{Meta: cm, Object: cm}, q)
+		Expect(q.Len()).To(Equal(1))
+		i, _ := q.Get()
+		Expect(i).To(Equal(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "other", Name: "foo"}}))
+	})
+
+	It("should not enqueue anything for objects without owner or owned by another type", func() {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "baz"}}
+		instance.Generic(event.GenericEvent{Meta: cm, Object: cm}, q)
+
+		cm = ownedBy(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "foo"}}, "baz")
+		instance.Create(event.CreateEvent{Meta: cm, Object: cm}, q)
+		Expect(q.Len()).To(Equal(0))
+	})
+
+	It("should fail to inject a scheme which doesn't know the owner type", func() {
+		_, err := inject.SchemeInto(runtime.NewScheme(), &handler.EnqueueRequestForAnnotationOwner{OwnerType: &rbacv1.ClusterRole{}})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,140 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+	"sigs.k8s.io/controller-runtime/pkg/internal/objectutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+var indexLog = logf.RuntimeLog.WithName("eventhandler").WithName("EnqueueRequestsFromIndex")
+
+// EnqueueRequestsFromIndex returns an EventHandler which enqueues Requests for the objects of
+// ownerType which refer to the object of an event, e.g. for the Deployments that mount a Secret.
+//
+// The referring objects are looked up in the cache through the field index named indexName, which
+// must have been registered on ownerType with the manager's FieldIndexer (see client.FieldIndexer),
+// and must map each object to the names of the objects it refers to.  Objects are looked up in the
+// namespace of the event's object, or in all namespaces if it is cluster-scoped.
+func EnqueueRequestsFromIndex(ownerType runtime.Object, indexName string) EventHandler {
+	return &enqueueRequestsFromIndex{ownerType: ownerType, indexName: indexName}
+}
+
+// enqueueRequestsFromIndex implements EnqueueRequestsFromIndex.
+type enqueueRequestsFromIndex struct {
+	ownerType runtime.Object
+	indexName string
+
+	// scheme is used to create lists of ownerType
+	scheme *runtime.Scheme
+
+	// reader reads from the cache the index is registered with
+	reader client.Reader
+}
+
+var _ inject.Scheme = &enqueueRequestsFromIndex{}
+var _ inject.Cache = &enqueueRequestsFromIndex{}
+
+// Create implements EventHandler
+func (e *enqueueRequestsFromIndex) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(evt.Meta, q)
+}
+
+// Update implements EventHandler
+func (e *enqueueRequestsFromIndex) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(evt.MetaNew, q)
+}
+
+// Delete implements EventHandler
+func (e *enqueueRequestsFromIndex) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(evt.Meta, q)
+}
+
+// Generic implements EventHandler
+func (e *enqueueRequestsFromIndex) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(evt.Meta, q)
+}
+
+// enqueue enqueues Requests for the objects referring to obj.
+func (e *enqueueRequestsFromIndex) enqueue(obj metav1.Object, q workqueue.RateLimitingInterface) {
+	if obj == nil {
+		indexLog.Error(nil, "Event has no metadata", "index", e.indexName)
+		return
+	}
+	if e.reader == nil {
+		indexLog.Error(nil, "No cache was injected, cannot look up referring objects", "index", e.indexName)
+		return
+	}
+
+	list, err := objectutil.NewListFor(e.ownerType, e.scheme)
+	if err != nil {
+		indexLog.Error(err, "Could not create a list of the owner type", "owner type", fmt.Sprintf("%T", e.ownerType))
+		return
+	}
+	if err := e.reader.List(context.TODO(), list,
+		client.InNamespace(obj.GetNamespace()), client.MatchingFields{e.indexName: obj.GetName()}); err != nil {
+		indexLog.Error(err, "Could not list referring objects", "index", e.indexName,
+			"namespace", obj.GetNamespace(), "name", obj.GetName())
+		return
+	}
+
+	err = meta.EachListItem(list, func(item runtime.Object) error {
+		m, err := meta.Accessor(item)
+		if err != nil {
+			return err
+		}
+		q.Add(reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: m.GetNamespace(),
+			Name:      m.GetName(),
+		}})
+		return nil
+	})
+	if err != nil {
+		indexLog.Error(err, "Could not enqueue referring objects", "index", e.indexName)
+	}
+}
+
+// InjectScheme is called by the Controller to provide a singleton scheme to the handler.
+func (e *enqueueRequestsFromIndex) InjectScheme(s *runtime.Scheme) error {
+	// check that lists of ownerType can be created
+	if _, err := objectutil.NewListFor(e.ownerType, s); err != nil {
+		return err
+	}
+	e.scheme = s
+	return nil
+}
+
+// InjectCache is called by the Controller to provide the cache the index is registered with.
+func (e *enqueueRequestsFromIndex) InjectCache(c cache.Cache) error {
+	if e.reader == nil {
+		e.reader = c
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+var _ = Describe("EnqueueRequestsFromIndex", func() {
+	var q workqueue.RateLimitingInterface
+	var c cache.Cache
+	var stop chan struct{}
+	var instance handler.EventHandler
+
+	deployment := func(namespace, name string, secrets ...string) *appsv1.Deployment {
+		d := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+		for _, s := range secrets {
+			d.Spec.Template.Spec.Volumes = append(d.Spec.Template.Spec.Volumes, corev1.Volume{
+				Name:         s,
+				VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: s}},
+			})
+		}
+		return d
+	}
+
+	BeforeEach(func() {
+		q = controllertest.Queue{Interface: workqueue.New()}
+		stop = make(chan struct{})
+
+		cl := fake.NewFakeClientWithScheme(scheme.Scheme,
+			deployment("biz", "uses-both", "foo", "bar"),
+			deployment("biz", "uses-foo", "foo"),
+			deployment("biz", "uses-none"),
+			deployment("other", "uses-foo", "foo"),
+		)
+		var err error
+		c, err = informertest.NewFakeCache(scheme.Scheme, cl)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.IndexField(context.Background(), &appsv1.Deployment{}, "secretNames", func(obj runtime.Object) []string {
+			var names []string
+			for _, v := range obj.(*appsv1.Deployment).Spec.Template.Spec.Volumes {
+				if v.Secret != nil {
+					names = append(names, v.Secret.SecretName)
+				}
+			}
+			return names
+		})).To(Succeed())
+		go func() {
+			defer GinkgoRecover()
+			Expect(c.Start(stop)).To(Succeed())
+		}()
+		Expect(c.WaitForCacheSync(stop)).To(BeTrue())
+
+		instance = handler.EnqueueRequestsFromIndex(&appsv1.Deployment{}, "secretNames")
+		Expect(inject.SchemeInto(scheme.Scheme, instance)).To(BeTrue())
+		Expect(inject.CacheInto(c, instance)).To(BeTrue())
+	})
+
+	AfterEach(func() {
+		close(stop)
+	})
+
+	secret := func(namespace, name string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+
+	drain := func() []interface{} {
+		var items []interface{}
+		for q.Len() > 0 {
+			i, _ := q.Get()
+			q.Done(i)
+			items = append(items, i)
+		}
+		return items
+	}
+
+	request := func(namespace, name string) reconcile.Request {
+		return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}
+	}
+
+	It("should enqueue the objects referring to the object of the event in its namespace", func() {
+		s := secret("biz", "foo")
+		instance.Create(event.CreateEvent{Meta: s, Object: s}, q)
+		Expect(drain()).To(ConsistOf(request("biz", "uses-both"), request("biz", "uses-foo")))
+
+		s = secret("biz", "bar")
+		instance.Update(event.UpdateEvent{MetaOld: s, ObjectOld: s, MetaNew: s, ObjectNew: s}, q)
+		Expect(drain()).To(ConsistOf(request("biz", "uses-both")))
+
+		s = secret("other", "foo")
+		instance.Delete(event.DeleteEvent{Meta: s, Object: s}, q)
+		Expect(drain()).To(ConsistOf(request("other", "uses-foo")))
+	})
+
+	It("should not enqueue anything if no object refers to the object of the event", func() {
+		s := secret("biz", "unused")
+		instance.Generic(event.GenericEvent{Meta: s, Object: s}, q)
+		Expect(q.Len()).To(Equal(0))
+	})
+
+	It("should fail to inject a scheme which doesn't know the owner type", func() {
+		instance = handler.EnqueueRequestsFromIndex(&appsv1.Deployment{}, "secretNames")
+		_, err := inject.SchemeInto(runtime.NewScheme(), instance)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/internal/handlerutil"
+)
+
+// PriorityFuncs returns the priority of the requests enqueued for each type of event.
+// Events whose function is nil get the priorityqueue.DefaultPriority.
+type PriorityFuncs struct {
+	// CreateFunc returns the priority of the requests enqueued for a CreateEvent
+	CreateFunc func(event.CreateEvent) int
+
+	// UpdateFunc returns the priority of the requests enqueued for an UpdateEvent
+	UpdateFunc func(event.UpdateEvent) int
+
+	// DeleteFunc returns the priority of the requests enqueued for a DeleteEvent
+	DeleteFunc func(event.DeleteEvent) int
+
+	// GenericFunc returns the priority of the requests enqueued for a GenericEvent
+	GenericFunc func(event.GenericEvent) int
+}
+
+// WithPriority returns an EventHandler adding the requests enqueued by h with the priority
+// returned by priorities for each event, when the queue of the controller is a
+// priorityqueue.PriorityQueue (see controller.Options.UsePriorityQueue).  With other queues,
+// requests are added as h adds them.  Requests added by h with AddAfter or AddRateLimited
+// keep the priority they are queued or being reconciled with, like the requests requeued
+// by the controller, and get the priorityqueue.DefaultPriority otherwise.
+//
+// For example, to handle changes before the objects of the initial list or of periodic
+// resyncs:
+//
+//	handler.WithPriority(&handler.EnqueueRequestForObject{}, handler.PriorityFuncs{
+//	    CreateFunc: func(e event.CreateEvent) int {
+//	        if e.Meta.GetCreationTimestamp().Time.Before(startTime) {
+//	            return priorityqueue.LowPriority
+//	        }
+//	        return priorityqueue.HighPriority
+//	    },
+//	    UpdateFunc: func(event.UpdateEvent) int { return priorityqueue.HighPriority },
+//	    DeleteFunc: func(event.DeleteEvent) int { return priorityqueue.HighPriority },
+//	    GenericFunc: func(event.GenericEvent) int { return priorityqueue.LowPriority },
+//	})
+func WithPriority(h EventHandler, priorities PriorityFuncs) EventHandler {
+	return &handlerutil.QueueWrapper{
+		Handler: h,
+		Queue: func(evt interface{}, q workqueue.RateLimitingInterface) workqueue.RateLimitingInterface {
+			return withPriority(q, priorities.priorityOf(evt))
+		},
+	}
+}
+
+// priorityOf returns the priority of the requests enqueued for evt.
+func (p PriorityFuncs) priorityOf(evt interface{}) int {
+	switch e := evt.(type) {
+	case event.CreateEvent:
+		if p.CreateFunc != nil {
+			return p.CreateFunc(e)
+		}
+	case event.UpdateEvent:
+		if p.UpdateFunc != nil {
+			return p.UpdateFunc(e)
+		}
+	case event.DeleteEvent:
+		if p.DeleteFunc != nil {
+			return p.DeleteFunc(e)
+		}
+	case event.GenericEvent:
+		if p.GenericFunc != nil {
+			return p.GenericFunc(e)
+		}
+	}
+	return priorityqueue.DefaultPriority
+}
+
+// withPriority returns a queue adding items to q with the given priority, if q is a PriorityQueue.
+func withPriority(q workqueue.RateLimitingInterface, priority int) workqueue.RateLimitingInterface {
+	if pq, ok := q.(priorityqueue.PriorityQueue); ok {
+		return priorityAdder{PriorityQueue: pq, priority: priority}
+	}
+	return q
+}
+
+// priorityAdder is a PriorityQueue whose Add uses a fixed priority.
+type priorityAdder struct {
+	priorityqueue.PriorityQueue
+	priority int
+}
+
+// Add implements workqueue.Interface
+func (q priorityAdder) Add(item interface{}) {
+	q.AddWithPriority(item, q.priority)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("WithPriority", func() {
+	var q priorityqueue.PriorityQueue
+	var instance handler.EventHandler
+
+	request := func(name string) reconcile.Request {
+		return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "biz", Name: name}}
+	}
+
+	BeforeEach(func() {
+		q = priorityqueue.New(workqueue.DefaultControllerRateLimiter())
+		instance = handler.WithPriority(&handler.EnqueueRequestForObject{}, handler.PriorityFuncs{
+			CreateFunc:  func(event.CreateEvent) int { return priorityqueue.LowPriority },
+			UpdateFunc:  func(event.UpdateEvent) int { return priorityqueue.HighPriority },
+			GenericFunc: func(event.GenericEvent) int { return priorityqueue.LowPriority },
+		})
+	})
+
+	AfterEach(func() {
+		q.ShutDown()
+	})
+
+	It("should enqueue requests with the priority of their event", func() {
+		listed := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "listed"}}
+		updated := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "updated"}}
+		deleted := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "deleted"}}
+		resynced := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "resynced"}}
+		instance.Create(event.CreateEvent{Meta: listed, Object: listed}, q)
+		instance.Generic(event.GenericEvent{Meta: resynced, Object: resynced}, q)
+		instance.Delete(event.DeleteEvent{Meta: deleted, Object: deleted}, q)
+		instance.Update(event.UpdateEvent{MetaOld: updated, ObjectOld: updated, MetaNew: updated, ObjectNew: updated}, q)
+
+		var items []interface{}
+		for q.Len() > 0 {
+			item, _ := q.Get()
+			q.Done(item)
+			items = append(items, item)
+		}
+		Expect(items).To(Equal([]interface{}{request("updated"), request("deleted"), request("listed"), request("resynced")}))
+	})
+
+	It("should enqueue requests normally into other queues", func() {
+		other := controllertest.Queue{Interface: workqueue.New()}
+		foo := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "foo"}}
+		instance.Update(event.UpdateEvent{MetaOld: foo, ObjectOld: foo, MetaNew: foo, ObjectNew: foo}, other)
+		Expect(other.Len()).To(Equal(1))
+		item, _ := other.Get()
+		Expect(item).To(Equal(request("foo")))
+	})
+
+	It("should add new requests after a delay with the default priority", func() {
+		delayed := handler.WithPriority(handler.Funcs{
+			CreateFunc: func(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+				q.AddAfter(request(evt.Meta.GetName()), time.Millisecond)
+			},
+		}, handler.PriorityFuncs{
+			CreateFunc: func(event.CreateEvent) int { return priorityqueue.HighPriority },
+		})
+		foo := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "foo"}}
+		delayed.Create(event.CreateEvent{Meta: foo, Object: foo}, q)
+		Eventually(q.Len).Should(Equal(1))
+		q.AddWithPriority("urgent", priorityqueue.HighPriority)
+
+		item, _ := q.Get()
+		Expect(item).To(Equal("urgent"))
+	})
+})
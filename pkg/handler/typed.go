@@ -0,0 +1,191 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+var typedLog = logf.RuntimeLog.WithName("eventhandler").WithName("Typed")
+
+// TypedEventHandler enqueues reconcile.Requests in response to events for objects of type T.
+// Use Untyped to use it wherever an EventHandler is expected.
+type TypedEventHandler[T runtime.Object] interface {
+	// Create is called in response to an create event - e.g. Pod Creation.
+	Create(event.TypedCreateEvent[T], workqueue.RateLimitingInterface)
+
+	// Update is called in response to an update event -  e.g. Pod Updated.
+	Update(event.TypedUpdateEvent[T], workqueue.RateLimitingInterface)
+
+	// Delete is called in response to a delete event - e.g. Pod Deleted.
+	Delete(event.TypedDeleteEvent[T], workqueue.RateLimitingInterface)
+
+	// Generic is called in response to an event of an unknown type or a synthetic event triggered as a cron or
+	// external trigger request - e.g. reconcile Autoscaling, or a Webhook.
+	Generic(event.TypedGenericEvent[T], workqueue.RateLimitingInterface)
+}
+
+// TypedFuncs implements TypedEventHandler.
+type TypedFuncs[T runtime.Object] struct {
+	// Create is called in response to an add event.  Defaults to no-op.
+	// RateLimitingInterface is used to enqueue reconcile.Requests.
+	CreateFunc func(event.TypedCreateEvent[T], workqueue.RateLimitingInterface)
+
+	// Update is called in response to an update event.  Defaults to no-op.
+	// RateLimitingInterface is used to enqueue reconcile.Requests.
+	UpdateFunc func(event.TypedUpdateEvent[T], workqueue.RateLimitingInterface)
+
+	// Delete is called in response to a delete event.  Defaults to no-op.
+	// RateLimitingInterface is used to enqueue reconcile.Requests.
+	DeleteFunc func(event.TypedDeleteEvent[T], workqueue.RateLimitingInterface)
+
+	// GenericFunc is called in response to a generic event.  Defaults to no-op.
+	// RateLimitingInterface is used to enqueue reconcile.Requests.
+	GenericFunc func(event.TypedGenericEvent[T], workqueue.RateLimitingInterface)
+}
+
+// Create implements TypedEventHandler
+func (h TypedFuncs[T]) Create(e event.TypedCreateEvent[T], q workqueue.RateLimitingInterface) {
+	if h.CreateFunc != nil {
+		h.CreateFunc(e, q)
+	}
+}
+
+// Delete implements TypedEventHandler
+func (h TypedFuncs[T]) Delete(e event.TypedDeleteEvent[T], q workqueue.RateLimitingInterface) {
+	if h.DeleteFunc != nil {
+		h.DeleteFunc(e, q)
+	}
+}
+
+// Update implements TypedEventHandler
+func (h TypedFuncs[T]) Update(e event.TypedUpdateEvent[T], q workqueue.RateLimitingInterface) {
+	if h.UpdateFunc != nil {
+		h.UpdateFunc(e, q)
+	}
+}
+
+// Generic implements TypedEventHandler
+func (h TypedFuncs[T]) Generic(e event.TypedGenericEvent[T], q workqueue.RateLimitingInterface) {
+	if h.GenericFunc != nil {
+		h.GenericFunc(e, q)
+	}
+}
+
+// TypedMapFunc maps an object of type T to a collection of keys to be enqueued.
+type TypedMapFunc[T runtime.Object] func(T) []reconcile.Request
+
+// TypedEnqueueRequestsFromMapFunc is the typed counterpart of EnqueueRequestsFromMapFunc: it enqueues
+// the Requests returned by ToRequests for the object of each event.
+//
+// For UpdateEvents which contain both a new and old object, the transformation function is run on both
+// objects and both sets of Requests are enqueue.
+type TypedEnqueueRequestsFromMapFunc[T runtime.Object] struct {
+	// ToRequests transforms the argument into a slice of keys to be reconciled
+	ToRequests TypedMapFunc[T]
+}
+
+// Create implements TypedEventHandler
+func (e *TypedEnqueueRequestsFromMapFunc[T]) Create(evt event.TypedCreateEvent[T], q workqueue.RateLimitingInterface) {
+	e.mapAndEnqueue(q, evt.Object)
+}
+
+// Update implements TypedEventHandler
+func (e *TypedEnqueueRequestsFromMapFunc[T]) Update(evt event.TypedUpdateEvent[T], q workqueue.RateLimitingInterface) {
+	e.mapAndEnqueue(q, evt.ObjectOld)
+	e.mapAndEnqueue(q, evt.ObjectNew)
+}
+
+// Delete implements TypedEventHandler
+func (e *TypedEnqueueRequestsFromMapFunc[T]) Delete(evt event.TypedDeleteEvent[T], q workqueue.RateLimitingInterface) {
+	e.mapAndEnqueue(q, evt.Object)
+}
+
+// Generic implements TypedEventHandler
+func (e *TypedEnqueueRequestsFromMapFunc[T]) Generic(evt event.TypedGenericEvent[T], q workqueue.RateLimitingInterface) {
+	e.mapAndEnqueue(q, evt.Object)
+}
+
+func (e *TypedEnqueueRequestsFromMapFunc[T]) mapAndEnqueue(q workqueue.RateLimitingInterface, obj T) {
+	for _, req := range e.ToRequests(obj) {
+		q.Add(req)
+	}
+}
+
+// Untyped adapts a TypedEventHandler to an EventHandler, so that it can be used with
+// any source.  Events for objects that are not of type T are dropped.
+func Untyped[T runtime.Object](h TypedEventHandler[T]) EventHandler {
+	return &untyped[T]{typed: h}
+}
+
+// untyped implements EventHandler on top of a TypedEventHandler.
+type untyped[T runtime.Object] struct {
+	typed TypedEventHandler[T]
+}
+
+// Create implements EventHandler
+func (h *untyped[T]) Create(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+	te, ok := event.ToTypedCreateEvent[T](e)
+	if !ok {
+		typedLog.Error(nil, "CreateEvent received for an object of unexpected type", "event", e)
+		return
+	}
+	h.typed.Create(te, q)
+}
+
+// Update implements EventHandler
+func (h *untyped[T]) Update(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	te, ok := event.ToTypedUpdateEvent[T](e)
+	if !ok {
+		typedLog.Error(nil, "UpdateEvent received for an object of unexpected type", "event", e)
+		return
+	}
+	h.typed.Update(te, q)
+}
+
+// Delete implements EventHandler
+func (h *untyped[T]) Delete(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	te, ok := event.ToTypedDeleteEvent[T](e)
+	if !ok {
+		typedLog.Error(nil, "DeleteEvent received for an object of unexpected type", "event", e)
+		return
+	}
+	h.typed.Delete(te, q)
+}
+
+// Generic implements EventHandler
+func (h *untyped[T]) Generic(e event.GenericEvent, q workqueue.RateLimitingInterface) {
+	te, ok := event.ToTypedGenericEvent[T](e)
+	if !ok {
+		typedLog.Error(nil, "GenericEvent received for an object of unexpected type", "event", e)
+		return
+	}
+	h.typed.Generic(te, q)
+}
+
+// InjectFunc implements inject.Injector, injecting fields into the typed handler.
+func (h *untyped[T]) InjectFunc(f inject.Func) error {
+	if f == nil {
+		return nil
+	}
+	return f(h.typed)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("TypedEventHandler", func() {
+	var q workqueue.RateLimitingInterface
+	var pod *corev1.Pod
+	var instance handler.EventHandler
+
+	BeforeEach(func() {
+		q = controllertest.Queue{Interface: workqueue.New()}
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "baz"},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+		}
+		instance = handler.Untyped[*corev1.Pod](&handler.TypedEnqueueRequestsFromMapFunc[*corev1.Pod]{
+			ToRequests: func(pod *corev1.Pod) []reconcile.Request {
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: pod.Spec.NodeName}}}
+			},
+		})
+	})
+
+	nodeRequest := func(name string) reconcile.Request {
+		return reconcile.Request{NamespacedName: types.NamespacedName{Name: name}}
+	}
+
+	It("should pass typed objects to the map function for each kind of event", func() {
+		instance.Create(event.CreateEvent{Meta: pod, Object: pod}, q)
+		instance.Delete(event.DeleteEvent{Meta: pod, Object: pod}, q)
+		instance.Generic(event.GenericEvent{Meta: pod, Object: pod}, q)
+		Expect(q.Len()).To(Equal(1))
+		i, _ := q.Get()
+		Expect(i).To(Equal(nodeRequest("node-1")))
+		q.Done(i)
+	})
+
+	It("should map both objects of UpdateEvents", func() {
+		moved := pod.DeepCopy()
+		moved.Spec.NodeName = "node-2"
+		instance.Update(event.UpdateEvent{MetaOld: pod, ObjectOld: pod, MetaNew: moved, ObjectNew: moved}, q)
+		Expect(q.Len()).To(Equal(2))
+		i1, _ := q.Get()
+		i2, _ := q.Get()
+		Expect([]interface{}{i1, i2}).To(ConsistOf(nodeRequest("node-1"), nodeRequest("node-2")))
+	})
+
+	It("should drop events for objects of other types", func() {
+		deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "baz"}}
+		instance.Create(event.CreateEvent{Meta: deploy, Object: deploy}, q)
+		Expect(q.Len()).To(Equal(0))
+	})
+
+	It("should call the functions of TypedFuncs", func() {
+		var created *corev1.Pod
+		instance = handler.Untyped[*corev1.Pod](handler.TypedFuncs[*corev1.Pod]{
+			CreateFunc: func(e event.TypedCreateEvent[*corev1.Pod], _ workqueue.RateLimitingInterface) {
+				created = e.Object
+			},
+		})
+		instance.Create(event.CreateEvent{Meta: pod, Object: pod}, q)
+		instance.Delete(event.DeleteEvent{Meta: pod, Object: pod}, q)
+		Expect(created).To(BeIdenticalTo(pod))
+	})
+})
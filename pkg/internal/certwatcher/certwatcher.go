@@ -18,6 +18,9 @@ package certwatcher
 
 import (
 	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
 	"sync"
 
 	"github.com/fsnotify/fsnotify"
@@ -26,26 +29,36 @@ import (
 
 var log = logf.RuntimeLog.WithName("certwatcher")
 
-// CertWatcher watches certificate and key files for changes.  When either file
-// changes, it reads and parses both and calls an optional callback with the new
-// certificate.
+// CertWatcher watches certificate and key files, and optionally a bundle of
+// client CA certificates, for changes.  When any file changes, it reads and
+// parses them again.
 type CertWatcher struct {
 	sync.Mutex
 
-	currentCert *tls.Certificate
-	watcher     *fsnotify.Watcher
+	currentCert      *tls.Certificate
+	currentClientCAs *x509.CertPool
+	watcher          *fsnotify.Watcher
 
-	certPath string
-	keyPath  string
+	certPath     string
+	keyPath      string
+	clientCAPath string
 }
 
 // New returns a new CertWatcher watching the given certificate and key.
 func New(certPath, keyPath string) (*CertWatcher, error) {
+	return NewWithClientCA(certPath, keyPath, "")
+}
+
+// NewWithClientCA returns a new CertWatcher watching the given certificate
+// and key, as well as the given bundle of CA certificates used to verify
+// client certificates, if clientCAPath is not empty.
+func NewWithClientCA(certPath, keyPath, clientCAPath string) (*CertWatcher, error) {
 	var err error
 
 	cw := &CertWatcher{
-		certPath: certPath,
-		keyPath:  keyPath,
+		certPath:     certPath,
+		keyPath:      keyPath,
+		clientCAPath: clientCAPath,
 	}
 
 	// Initial read of certificate and key.
@@ -68,9 +81,20 @@ func (cw *CertWatcher) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate,
 	return cw.currentCert, nil
 }
 
+// GetClientCAs fetches the currently loaded pool of client CA certificates,
+// which is nil if no client CA bundle is watched.
+func (cw *CertWatcher) GetClientCAs() *x509.CertPool {
+	cw.Lock()
+	defer cw.Unlock()
+	return cw.currentClientCAs
+}
+
 // Start starts the watch on the certificate and key files.
 func (cw *CertWatcher) Start(stopCh <-chan struct{}) error {
 	files := []string{cw.certPath, cw.keyPath}
+	if cw.clientCAPath != "" {
+		files = append(files, cw.clientCAPath)
+	}
 
 	for _, f := range files {
 		if err := cw.watcher.Add(f); err != nil {
@@ -111,17 +135,30 @@ func (cw *CertWatcher) Watch() {
 	}
 }
 
-// ReadCertificate reads the certificate and key files from disk, parses them,
-// and updates the current certificate on the watcher.  If a callback is set, it
-// is invoked with the new certificate.
+// ReadCertificate reads the certificate and key files, and the client CA
+// bundle if any, from disk, parses them, and updates the current certificate
+// and client CAs on the watcher.  Nothing is updated if any of them is invalid.
 func (cw *CertWatcher) ReadCertificate() error {
 	cert, err := tls.LoadX509KeyPair(cw.certPath, cw.keyPath)
 	if err != nil {
 		return err
 	}
 
+	var clientCAs *x509.CertPool
+	if cw.clientCAPath != "" {
+		clientCABytes, err := ioutil.ReadFile(cw.clientCAPath)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA cert: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(clientCABytes) {
+			return fmt.Errorf("failed to append client CA cert to CA pool")
+		}
+	}
+
 	cw.Lock()
 	cw.currentCert = &cert
+	cw.currentClientCAs = clientCAs
 	cw.Unlock()
 
 	log.Info("Updated current TLS certificate")
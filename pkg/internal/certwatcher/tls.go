@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certwatcher
+
+import (
+	"crypto/tls"
+	"errors"
+	"path/filepath"
+)
+
+// Options configures the TLS settings of a server.
+type Options struct {
+	// CertDir is the directory that contains the server key and certificate.
+	CertDir string
+
+	// CertName is the server certificate name. Defaults to tls.crt.
+	CertName string
+
+	// KeyName is the server key name. Defaults to tls.key.
+	KeyName string
+
+	// ClientCAName is the CA certificate name which the server uses to verify
+	// client certificates.  Defaults to "", which means client certificates
+	// are not verified.
+	ClientCAName string
+
+	// TLSOpts are functions applied to the TLS config in order, once it has
+	// been set up from the other options.
+	TLSOpts []func(*tls.Config)
+}
+
+// NewTLSConfig returns a TLS config for a server serving the certificate
+// described by opts.  The certificate, key and client CA bundle are reloaded
+// whenever they change, until the stop channel is closed.
+func NewTLSConfig(opts Options, stop <-chan struct{}) (*tls.Config, error) {
+	if opts.CertDir == "" {
+		return nil, errors.New("no directory to load the serving certificate from is configured")
+	}
+	if opts.CertName == "" {
+		opts.CertName = "tls.crt"
+	}
+	if opts.KeyName == "" {
+		opts.KeyName = "tls.key"
+	}
+
+	certPath := filepath.Join(opts.CertDir, opts.CertName)
+	keyPath := filepath.Join(opts.CertDir, opts.KeyName)
+	var clientCAPath string
+	if opts.ClientCAName != "" {
+		clientCAPath = filepath.Join(opts.CertDir, opts.ClientCAName)
+	}
+
+	certWatcher, err := NewWithClientCA(certPath, keyPath, clientCAPath)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		if err := certWatcher.Start(stop); err != nil {
+			log.Error(err, "certificate watcher error")
+		}
+	}()
+
+	cfg := &tls.Config{
+		NextProtos:     []string{"h2"},
+		GetCertificate: certWatcher.GetCertificate,
+	}
+
+	if clientCAPath != "" {
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		// use the client CAs loaded last for each new connection
+		cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			connCfg := cfg.Clone()
+			connCfg.GetConfigForClient = nil
+			connCfg.ClientCAs = certWatcher.GetClientCAs()
+			return connCfg, nil
+		}
+	}
+
+	for _, opt := range opts.TLSOpts {
+		opt(cfg)
+	}
+
+	return cfg, nil
+}
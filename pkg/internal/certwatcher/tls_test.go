@@ -0,0 +1,209 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certwatcher
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewTLSConfig", func() {
+	var dir string
+	var stop chan struct{}
+	var listeners []net.Listener
+	var serverCA *testCA
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "certwatcher")
+		Expect(err).NotTo(HaveOccurred())
+		stop = make(chan struct{})
+
+		serverCA = newTestCA("server-ca")
+		cert, key := serverCA.issue("localhost", x509.ExtKeyUsageServerAuth)
+		Expect(ioutil.WriteFile(filepath.Join(dir, "tls.crt"), cert, 0600)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "tls.key"), key, 0600)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		for _, listener := range listeners {
+			listener.Close()
+		}
+		listeners = nil
+		close(stop)
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	// serve accepts connections with the given config, completing their
+	// handshakes, until the spec ends.
+	serve := func(cfg *tls.Config) string {
+		listener, err := tls.Listen("tcp", "127.0.0.1:0", cfg)
+		Expect(err).NotTo(HaveOccurred())
+		listeners = append(listeners, listener)
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				go func() {
+					defer conn.Close()
+					_ = conn.(*tls.Conn).Handshake()
+				}()
+			}
+		}()
+		return listener.Addr().String()
+	}
+
+	dial := func(addr string, cfg *tls.Config) error {
+		cfg.RootCAs = serverCA.pool()
+		cfg.ServerName = "localhost"
+		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 5 * time.Second}, "tcp", addr, cfg)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		// client certificate errors are only reported once the server is done with the handshake
+		_, err = conn.Read(make([]byte, 1))
+		if err != nil && err.Error() == "EOF" {
+			return nil
+		}
+		return err
+	}
+
+	It("should serve the certificate of the directory", func() {
+		cfg, err := NewTLSConfig(Options{CertDir: dir}, stop)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dial(serve(cfg), &tls.Config{})).To(Succeed())
+	})
+
+	It("should fail if the certificate cannot be loaded", func() {
+		_, err := NewTLSConfig(Options{CertDir: dir, CertName: "missing.crt"}, stop)
+		Expect(err).To(HaveOccurred())
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("should apply the TLS options", func() {
+		cfg, err := NewTLSConfig(Options{CertDir: dir, TLSOpts: []func(*tls.Config){
+			func(c *tls.Config) { c.MinVersion = tls.VersionTLS13 },
+		}}, stop)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.MinVersion).To(BeEquivalentTo(tls.VersionTLS13))
+
+		addr := serve(cfg)
+		Expect(dial(addr, &tls.Config{MaxVersion: tls.VersionTLS12})).NotTo(Succeed())
+		Expect(dial(addr, &tls.Config{MinVersion: tls.VersionTLS13})).To(Succeed())
+	})
+
+	It("should verify client certificates against the client CA bundle, reloading it when it changes", func() {
+		oldCA, newCA := newTestCA("old-client-ca"), newTestCA("new-client-ca")
+		clientCAPath := filepath.Join(dir, "client-ca.crt")
+		Expect(ioutil.WriteFile(clientCAPath, oldCA.certPEM(), 0600)).To(Succeed())
+
+		cfg, err := NewTLSConfig(Options{CertDir: dir, ClientCAName: "client-ca.crt"}, stop)
+		Expect(err).NotTo(HaveOccurred())
+		addr := serve(cfg)
+
+		clientConfig := func(ca *testCA) *tls.Config {
+			cert, key := ca.issue("client", x509.ExtKeyUsageClientAuth)
+			pair, err := tls.X509KeyPair(cert, key)
+			Expect(err).NotTo(HaveOccurred())
+			return &tls.Config{Certificates: []tls.Certificate{pair}}
+		}
+
+		By("accepting certificates of the current CA only")
+		Expect(dial(addr, &tls.Config{})).NotTo(Succeed())
+		Expect(dial(addr, clientConfig(oldCA))).To(Succeed())
+		Expect(dial(addr, clientConfig(newCA))).NotTo(Succeed())
+
+		By("replacing the client CA bundle")
+		Expect(ioutil.WriteFile(clientCAPath, newCA.certPEM(), 0600)).To(Succeed())
+
+		Eventually(func() error { return dial(addr, clientConfig(newCA)) }).Should(Succeed())
+		Expect(dial(addr, clientConfig(oldCA))).NotTo(Succeed())
+	})
+})
+
+// testCA is a self-signed CA issuing certificates for tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(name string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	Expect(err).NotTo(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) certPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw})
+}
+
+func (ca *testCA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+// issue returns a PEM-encoded certificate and key for the given name.
+func (ca *testCA) issue(name string, usage x509.ExtKeyUsage) (cert, key []byte) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, priv.Public(), ca.key)
+	Expect(err).NotTo(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(priv)
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
@@ -44,6 +44,36 @@ var (
 		Name: "controller_runtime_reconcile_time_seconds",
 		Help: "Length of time per reconciliation per controller",
 	}, []string{"controller"})
+
+	// DebouncedEventsReceived is a prometheus counter metrics which holds the total
+	// number of events received by the debounced watches of a controller.
+	DebouncedEventsReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_debounced_events_received_total",
+		Help: "Total number of events received by debounced watches per controller",
+	}, []string{"controller"})
+
+	// DebouncedRequestsEnqueued is a prometheus counter metrics which holds the total
+	// number of requests the debounced watches of a controller enqueued, once the
+	// events for the same request have been merged.
+	DebouncedRequestsEnqueued = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_debounced_requests_enqueued_total",
+		Help: "Total number of requests enqueued by debounced watches per controller",
+	}, []string{"controller"})
+
+	// ChannelSourceEventsDropped is a prometheus counter metrics which holds the total
+	// number of events a channel source dropped because the buffer of one of its
+	// destinations was full.
+	ChannelSourceEventsDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_channel_source_events_dropped_total",
+		Help: "Total number of events dropped by channel sources because of full buffers per source",
+	}, []string{"name"})
+
+	// HTTPSourceRequests is a prometheus counter metrics which holds the total
+	// number of requests received by HTTP sources.
+	HTTPSourceRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_http_source_requests_total",
+		Help: "Total number of requests received by HTTP sources per path and status code",
+	}, []string{"path", "code"})
 )
 
 func init() {
@@ -51,6 +81,10 @@ func init() {
 		ReconcileTotal,
 		ReconcileErrors,
 		ReconcileTime,
+		DebouncedEventsReceived,
+		DebouncedRequestsEnqueued,
+		ChannelSourceEventsDropped,
+		HTTPSourceRequests,
 		// expose process metrics like CPU, Memory, file descriptor usage etc.
 		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
 		// expose Go runtime metrics like GC stats, memory stats etc.
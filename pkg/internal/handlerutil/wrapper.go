@@ -0,0 +1,73 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlerutil
+
+import (
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+// EventHandler is the handler.EventHandler interface, which can't be imported
+// here since the handler package uses this package.
+type EventHandler interface {
+	Create(event.CreateEvent, workqueue.RateLimitingInterface)
+	Update(event.UpdateEvent, workqueue.RateLimitingInterface)
+	Delete(event.DeleteEvent, workqueue.RateLimitingInterface)
+	Generic(event.GenericEvent, workqueue.RateLimitingInterface)
+}
+
+var _ EventHandler = &QueueWrapper{}
+var _ inject.Injector = &QueueWrapper{}
+
+// QueueWrapper is an event handler passing each event to Handler along with the
+// queue returned by Queue, so that the requests enqueued by Handler can be
+// altered on their way to the queue of the controller.
+type QueueWrapper struct {
+	// Handler is the wrapped event handler.
+	Handler EventHandler
+
+	// Queue returns the queue Handler enqueues the requests for evt into, given
+	// the queue of the controller.  evt is an event.CreateEvent, an
+	// event.UpdateEvent, an event.DeleteEvent or an event.GenericEvent.
+	Queue func(evt interface{}, q workqueue.RateLimitingInterface) workqueue.RateLimitingInterface
+}
+
+// Create implements EventHandler
+func (w *QueueWrapper) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	w.Handler.Create(evt, w.Queue(evt, q))
+}
+
+// Update implements EventHandler
+func (w *QueueWrapper) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	w.Handler.Update(evt, w.Queue(evt, q))
+}
+
+// Delete implements EventHandler
+func (w *QueueWrapper) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	w.Handler.Delete(evt, w.Queue(evt, q))
+}
+
+// Generic implements EventHandler
+func (w *QueueWrapper) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	w.Handler.Generic(evt, w.Queue(evt, q))
+}
+
+// InjectFunc implements inject.Injector, injecting fields into the wrapped handler.
+func (w *QueueWrapper) InjectFunc(f inject.Func) error {
+	return f(w.Handler)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlerutil_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/internal/handlerutil"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+var _ = Describe("QueueWrapper", func() {
+	It("should pass each event to the handler with the queue returned for it", func() {
+		q := controllertest.Queue{Interface: workqueue.New()}
+		wrapped := controllertest.Queue{Interface: workqueue.New()}
+		var events []interface{}
+		w := &handlerutil.QueueWrapper{
+			Handler: &handler.EnqueueRequestForObject{},
+			Queue: func(evt interface{}, got workqueue.RateLimitingInterface) workqueue.RateLimitingInterface {
+				defer GinkgoRecover()
+				Expect(got).To(Equal(q))
+				events = append(events, evt)
+				return wrapped
+			},
+		}
+
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "baz"}}
+		create := event.CreateEvent{Meta: pod, Object: pod}
+		update := event.UpdateEvent{MetaOld: pod, ObjectOld: pod, MetaNew: pod, ObjectNew: pod}
+		del := event.DeleteEvent{Meta: pod, Object: pod}
+		generic := event.GenericEvent{Meta: pod, Object: pod}
+		w.Create(create, q)
+		w.Update(update, q)
+		w.Delete(del, q)
+		w.Generic(generic, q)
+
+		Expect(events).To(Equal([]interface{}{create, update, del, generic}))
+		Expect(q.Len()).To(Equal(0))
+		Expect(wrapped.Len()).To(Equal(1))
+	})
+
+	It("should inject fields into the wrapped handler", func() {
+		owner := &handler.EnqueueRequestForOwner{OwnerType: &corev1.Pod{}}
+		w := &handlerutil.QueueWrapper{Handler: owner}
+		var injected interface{}
+		Expect(inject.InjectorInto(func(i interface{}) error {
+			injected = i
+			return nil
+		}, w)).To(BeTrue())
+		Expect(injected).To(BeIdenticalTo(owner))
+	})
+})
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/internal/testing/integration/addr"
@@ -68,7 +70,20 @@ type APIServer struct {
 	Out io.Writer
 	Err io.Writer
 
+	// Detached, if set, starts the APIServer such that it keeps running after
+	// the current process exits.  Its output is then written to a log file in
+	// the CertDir, and Out and Err are ignored.
+	Detached bool
+
 	processState *internal.ProcessState
+
+	// clientCA signs client certs for the users added to this APIServer.  It's
+	// nil if the CertDir already contained a client CA.
+	clientCA *internal.TinyCA
+
+	// servingCAData is the PEM-encoded CA that signed the serving cert, if it
+	// was generated by us.
+	servingCAData []byte
 }
 
 // Start starts the apiserver, waits for it to come up, and returns an error,
@@ -112,6 +127,7 @@ func (s *APIServer) setProcessState() error {
 	}
 
 	s.processState.HealthCheckEndpoint = "/healthz"
+	s.processState.Detached = s.Detached
 
 	s.URL = &s.processState.URL
 	s.CertDir = s.processState.Dir
@@ -130,16 +146,20 @@ func (s *APIServer) setProcessState() error {
 }
 
 func (s *APIServer) populateAPIServerCerts() error {
-	_, statErr := os.Stat(filepath.Join(s.CertDir, "apiserver.crt"))
-	if !os.IsNotExist(statErr) {
-		return statErr
-	}
-
 	ca, err := internal.NewTinyCA()
 	if err != nil {
 		return err
 	}
 
+	if err := s.populateClientCA(ca); err != nil {
+		return err
+	}
+
+	_, statErr := os.Stat(filepath.Join(s.CertDir, "apiserver.crt"))
+	if !os.IsNotExist(statErr) {
+		return statErr
+	}
+
 	certs, err := ca.NewServingCert()
 	if err != nil {
 		return err
@@ -156,10 +176,71 @@ func (s *APIServer) populateAPIServerCerts() error {
 	if err := ioutil.WriteFile(filepath.Join(s.CertDir, "apiserver.key"), keyData, 0640); err != nil {
 		return err
 	}
+	s.servingCAData = ca.CA.CertBytes()
 
 	return nil
 }
 
+// populateClientCA writes the given CA to the CertDir as the CA used to
+// verify client certs, unless one is already there.
+func (s *APIServer) populateClientCA(ca *internal.TinyCA) error {
+	caPath := filepath.Join(s.CertDir, "client-ca.crt")
+	_, statErr := os.Stat(caPath)
+	if !os.IsNotExist(statErr) {
+		return statErr
+	}
+
+	if err := ioutil.WriteFile(caPath, ca.CA.CertBytes(), 0640); err != nil {
+		return err
+	}
+	s.clientCA = ca
+
+	return nil
+}
+
+// SecureURL returns the URL of the secure port of the APIServer, on which
+// requests are authenticated and authorized.
+func (s *APIServer) SecureURL() *url.URL {
+	host := "127.0.0.1"
+	if s.URL != nil {
+		host = s.URL.Hostname()
+	}
+	return &url.URL{
+		Scheme: "https",
+		Host:   net.JoinHostPort(host, strconv.Itoa(s.SecurePort)),
+	}
+}
+
+// NewClientCert provisions a client certificate authenticating as the given
+// user and groups against this APIServer, returning the PEM-encoded
+// certificate and key.  It returns an error if the APIServer hasn't been
+// started yet, or if its client CA was not generated by us.
+func (s *APIServer) NewClientCert(user string, groups []string) (cert []byte, key []byte, err error) {
+	if s.clientCA == nil {
+		return nil, nil, fmt.Errorf("no client CA available to sign certs, the APIServer must be started with a generated client CA")
+	}
+	certs, err := s.clientCA.NewClientCert(user, groups)
+	if err != nil {
+		return nil, nil, err
+	}
+	return certs.AsBytes()
+}
+
+// ServingCAData returns the PEM-encoded CA that signed the APIServer's
+// serving cert, or nil if the serving cert was provided in the CertDir.
+func (s *APIServer) ServingCAData() []byte {
+	return s.servingCAData
+}
+
+// PID returns the process ID of the running APIServer, or 0 if it has not been
+// started.
+func (s *APIServer) PID() int {
+	if s.processState == nil {
+		return 0
+	}
+	return s.processState.PID()
+}
+
 // Stop stops this process gracefully, waits for its termination, and cleans up
 // the CertDir if necessary.
 func (s *APIServer) Stop() error {
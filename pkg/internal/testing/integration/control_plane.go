@@ -84,3 +84,37 @@ func (f *ControlPlane) RESTClientConfig() (*rest.Config, error) {
 	err := rest.SetKubernetesDefaults(c)
 	return c, err
 }
+
+// AddUser returns a restconfig authenticating as the given user and groups via
+// a client certificate, ready to connect to the secure port of this
+// ControlPlane.  Unlike the config returned by RESTClientConfig, requests made
+// with it are subject to authorization, so the user only has the permissions
+// granted to it (or its groups) via RBAC.  Members of the "system:masters"
+// group are allowed to do anything.
+func (f *ControlPlane) AddUser(user string, groups []string) (*rest.Config, error) {
+	if f.APIServer == nil {
+		return nil, fmt.Errorf("the control plane has not been started")
+	}
+	certData, keyData, err := f.APIServer.NewClientCert(user, groups)
+	if err != nil {
+		return nil, fmt.Errorf("unable to provision client cert for user %q: %w", user, err)
+	}
+
+	c := &rest.Config{
+		Host: f.APIServer.SecureURL().String(),
+		TLSClientConfig: rest.TLSClientConfig{
+			CertData: certData,
+			KeyData:  keyData,
+			CAData:   f.APIServer.ServingCAData(),
+		},
+		ContentConfig: rest.ContentConfig{
+			NegotiatedSerializer: serializer.WithoutConversionCodecFactory{CodecFactory: scheme.Codecs},
+		},
+	}
+	if c.TLSClientConfig.CAData == nil {
+		// we don't know who signed a serving cert we were handed, so we can't verify it
+		c.TLSClientConfig.Insecure = true
+	}
+	err = rest.SetKubernetesDefaults(c)
+	return c, err
+}
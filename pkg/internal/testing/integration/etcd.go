@@ -55,6 +55,11 @@ type Etcd struct {
 	Out io.Writer
 	Err io.Writer
 
+	// Detached, if set, starts Etcd such that it keeps running after the
+	// current process exits.  Its output is then written to a log file in the
+	// DataDir, and Out and Err are ignored.
+	Detached bool
+
 	processState *internal.ProcessState
 }
 
@@ -87,6 +92,11 @@ func (e *Etcd) setProcessState() error {
 	}
 
 	e.processState.StartMessage = internal.GetEtcdStartMessage(e.processState.URL)
+	if e.Detached {
+		// we can't watch the output of detached processes for the start message
+		e.processState.HealthCheckEndpoint = "/health"
+		e.processState.Detached = true
+	}
 
 	e.URL = &e.processState.URL
 	e.DataDir = e.processState.Dir
@@ -100,6 +110,15 @@ func (e *Etcd) setProcessState() error {
 	return err
 }
 
+// PID returns the process ID of the running Etcd, or 0 if it has not been
+// started.
+func (e *Etcd) PID() int {
+	if e.processState == nil {
+		return 0
+	}
+	return e.processState.PID()
+}
+
 // Stop stops this process gracefully, waits for its termination, and cleans up
 // the DataDir if necessary.
 func (e *Etcd) Stop() error {
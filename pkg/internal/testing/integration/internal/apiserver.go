@@ -9,6 +9,10 @@ var APIServerDefaultArgs = []string{
 	"--insecure-port={{ if .URL }}{{ .URL.Port }}{{ end }}",
 	"--insecure-bind-address={{ if .URL }}{{ .URL.Hostname }}{{ end }}",
 	"--secure-port={{ if .SecurePort }}{{ .SecurePort }}{{ end }}",
+	// client certs signed by this CA authenticate users on the secure port,
+	// whose requests are then authorized with RBAC (the insecure port bypasses both)
+	"--client-ca-file={{ .CertDir }}/client-ca.crt",
+	"--authorization-mode=RBAC",
 	// we're keeping this disabled because if enabled, default SA is missing which would force all tests to create one
 	// in normal apiserver operation this SA is created by controller, but that is not run in integration environment
 	"--disable-admission-plugins=ServiceAccount",
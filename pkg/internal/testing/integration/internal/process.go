@@ -10,7 +10,9 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"path/filepath"
 	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/onsi/gomega/gbytes"
@@ -42,6 +44,18 @@ type ProcessState struct {
 	StartMessage string
 	Args         []string
 
+	// Detached indicates that the process should keep running after the
+	// current process exits: it is started in its own process group, and its
+	// output is written to a log file in Dir rather than piped through the
+	// current process.  The readiness of detached processes can only be
+	// detected through HealthCheckEndpoint.
+	Detached bool
+
+	// detached is the running process, if it was started detached.  exited
+	// is closed once it has terminated.
+	detached *os.Process
+	exited   chan struct{}
+
 	// ready holds wether the process is currently in ready state (hit the ready condition) or not.
 	// It will be set to true on a successful `Start()` and set to false on a successful `Stop()`
 	ready bool
@@ -123,6 +137,10 @@ func (ps *ProcessState) Start(stdout, stderr io.Writer) (err error) {
 		return nil
 	}
 
+	if ps.Detached {
+		return ps.startDetached()
+	}
+
 	command := exec.Command(ps.Path, ps.Args...)
 
 	ready := make(chan bool)
@@ -160,6 +178,64 @@ func (ps *ProcessState) Start(stdout, stderr io.Writer) (err error) {
 	}
 }
 
+// startDetached starts the process detached from the current one, and waits
+// for its health check to pass.
+func (ps *ProcessState) startDetached() error {
+	if ps.HealthCheckEndpoint == "" {
+		return fmt.Errorf("detached process %s needs a health check endpoint", path.Base(ps.Path))
+	}
+
+	logFile, err := os.OpenFile(filepath.Join(ps.Dir, path.Base(ps.Path)+".log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+	// the process gets its own copy of the file descriptor
+	defer logFile.Close()
+
+	command := exec.Command(ps.Path, ps.Args...)
+	command.Stdout = logFile
+	command.Stderr = logFile
+	command.SysProcAttr = detachedProcAttr()
+	if err := command.Start(); err != nil {
+		return err
+	}
+	ps.detached = command.Process
+	ps.exited = make(chan struct{})
+	go func() {
+		// reap the process if it terminates while we're still around
+		_ = command.Wait()
+		close(ps.exited)
+	}()
+
+	ready := make(chan bool)
+	pollerStopCh := make(stopChannel)
+	healthCheckURL := ps.URL
+	healthCheckURL.Path = ps.HealthCheckEndpoint
+	go pollURLUntilOK(healthCheckURL, ps.HealthCheckPollInterval, ready, pollerStopCh)
+
+	select {
+	case <-ready:
+		ps.ready = true
+		return nil
+	case <-time.After(ps.StartTimeout):
+		close(pollerStopCh)
+		_ = ps.detached.Kill()
+		return fmt.Errorf("timeout waiting for process %s to start", path.Base(ps.Path))
+	}
+}
+
+// PID returns the process ID of the running process, or 0 if it has not been
+// started.
+func (ps *ProcessState) PID() int {
+	if ps.detached != nil {
+		return ps.detached.Pid
+	}
+	if ps.Session != nil && ps.Session.Command.Process != nil {
+		return ps.Session.Command.Process.Pid
+	}
+	return 0
+}
+
 func safeMultiWriter(writers ...io.Writer) io.Writer {
 	safeWriters := []io.Writer{}
 	for _, w := range writers {
@@ -196,6 +272,9 @@ func pollURLUntilOK(url url.URL, interval time.Duration, ready chan bool, stopCh
 // Stop stops this process gracefully, waits for its termination, and cleans up
 // the CertDir if necessary.
 func (ps *ProcessState) Stop() error {
+	if ps.detached != nil {
+		return ps.stopDetached()
+	}
 	if ps.Session == nil {
 		return nil
 	}
@@ -223,3 +302,22 @@ func (ps *ProcessState) Stop() error {
 
 	return nil
 }
+
+// stopDetached stops a detached process gracefully, waits for its termination,
+// and cleans up the Dir if necessary.
+func (ps *ProcessState) stopDetached() error {
+	if err := ps.detached.Signal(syscall.SIGTERM); err != nil {
+		// either it's gone already, or the platform has no SIGTERM
+		_ = ps.detached.Kill()
+	}
+	select {
+	case <-ps.exited:
+	case <-time.After(ps.StopTimeout):
+		return fmt.Errorf("timeout waiting for process %s to stop", path.Base(ps.Path))
+	}
+	ps.ready = false
+	if ps.DirNeedsCleaning {
+		return os.RemoveAll(ps.Dir)
+	}
+	return nil
+}
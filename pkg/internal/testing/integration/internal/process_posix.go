@@ -0,0 +1,11 @@
+// +build !windows
+
+package internal
+
+import "syscall"
+
+// detachedProcAttr starts processes in their own process group, so that they
+// don't receive the signals (e.g. Ctrl-C) sent to the current one.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true}
+}
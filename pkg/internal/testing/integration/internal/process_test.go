@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"time"
 
@@ -199,6 +200,47 @@ var _ = Describe("Start method", func() {
 		})
 	})
 
+	Context("when the process is detached", func() {
+		var server *ghttp.Server
+		BeforeEach(func() {
+			server = ghttp.NewServer()
+			server.RouteToHandler("GET", healthURLPath, ghttp.RespondWith(http.StatusOK, ""))
+
+			var err error
+			processState.Dir, err = ioutil.TempDir("", "k8s_test_framework_")
+			Expect(err).NotTo(HaveOccurred())
+			processState.DirNeedsCleaning = true
+			processState.Detached = true
+			processState.HealthCheckEndpoint = healthURLPath
+			processState.URL = getServerURL(server)
+			processState.StartTimeout = 1 * time.Second
+			processState.StopTimeout = 1 * time.Second
+		})
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("writes its output to a log file in its directory, and can be stopped", func() {
+			Expect(processState.Start(nil, nil)).To(Succeed())
+			Expect(processState.PID()).NotTo(BeZero())
+
+			logPath := filepath.Join(processState.Dir, "bash.log")
+			Eventually(func() (string, error) {
+				out, err := ioutil.ReadFile(logPath)
+				return string(out), err
+			}).Should(ContainSubstring("loop 1"))
+
+			Expect(processState.Stop()).To(Succeed())
+			Expect(processState.Dir).NotTo(BeAnExistingFile())
+		})
+
+		It("requires a health check endpoint", func() {
+			processState.HealthCheckEndpoint = ""
+			Expect(processState.Start(nil, nil)).To(MatchError(ContainSubstring("health check")))
+			Expect(os.RemoveAll(processState.Dir)).To(Succeed())
+		})
+	})
+
 	Context("when IO is configured", func() {
 		It("can inspect stdout & stderr", func() {
 			stdout := &bytes.Buffer{}
@@ -149,3 +149,13 @@ func (c *TinyCA) NewServingCert() (CertPair, error) {
 		Usages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 	})
 }
+
+// NewClientCert returns a new CertPair for authenticating to an API server as
+// the given user, belonging to the given groups.
+func (c *TinyCA) NewClientCert(user string, groups []string) (CertPair, error) {
+	return c.makeCert(certutil.Config{
+		CommonName:   user,
+		Organization: groups,
+		Usages:       []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+}
@@ -0,0 +1,30 @@
+package internal_test
+
+import (
+	"crypto/x509"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "sigs.k8s.io/controller-runtime/pkg/internal/testing/integration/internal"
+)
+
+var _ = Describe("TinyCA", func() {
+	It("signs client certs for the given user and groups", func() {
+		ca, err := NewTinyCA()
+		Expect(err).NotTo(HaveOccurred())
+
+		certs, err := ca.NewClientCert("alice", []string{"devs", "ops"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(certs.Cert.Subject.CommonName).To(Equal("alice"))
+		Expect(certs.Cert.Subject.Organization).To(ConsistOf("devs", "ops"))
+		Expect(certs.Cert.ExtKeyUsage).To(ConsistOf(x509.ExtKeyUsageClientAuth))
+
+		pool := x509.NewCertPool()
+		pool.AddCert(ca.CA.Cert)
+		_, err = certs.Cert.Verify(x509.VerifyOptions{
+			Roots:     pool,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -18,6 +18,7 @@ package manager
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
@@ -35,6 +36,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/internal/certwatcher"
 	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/recorder"
@@ -96,12 +98,18 @@ type controllerManager struct {
 	// metricsListener is used to serve prometheus metrics
 	metricsListener net.Listener
 
+	// metricsTLS configures serving metrics over TLS, if not nil.
+	metricsTLS *TLSOptions
+
 	// metricsExtraHandlers contains extra handlers to register on http server that serves metrics.
 	metricsExtraHandlers map[string]http.Handler
 
 	// healthProbeListener is used to serve liveness probe
 	healthProbeListener net.Listener
 
+	// healthProbeTLS configures serving health probes over TLS, if not nil.
+	healthProbeTLS *TLSOptions
+
 	// Readiness probe endpoint name
 	readinessEndpointName string
 
@@ -149,6 +157,8 @@ type controllerManager struct {
 	// if not set, webhook server would look up the server key and certificate in
 	// {TempDir}/k8s-webhook-server/serving-certs
 	certDir string
+	// tlsOpts configures the TLS config of the webhook server.
+	tlsOpts []func(*tls.Config)
 
 	webhookServer *webhook.Server
 
@@ -360,10 +370,15 @@ func (cm *controllerManager) GetWebhookServer() *webhook.Server {
 			Port:    cm.port,
 			Host:    cm.host,
 			CertDir: cm.certDir,
+			TLSOpts: cm.tlsOpts,
 		}
 		if err := cm.Add(cm.webhookServer); err != nil {
 			panic("unable to add webhookServer to the controller manager")
 		}
+		// the manager is only ready once the webhook server serves requests
+		if err := cm.AddReadyzCheck("webhook", cm.webhookServer.StartedChecker()); err != nil {
+			log.Error(err, "unable to add a readiness check for the webhook server")
+		}
 	}
 	return cm.webhookServer
 }
@@ -403,6 +418,25 @@ func (cm *controllerManager) serveMetrics(stop <-chan struct{}) {
 	}
 }
 
+// secureListener wraps the given listener to serve over TLS, if TLS options
+// are given.
+func (cm *controllerManager) secureListener(listener net.Listener, opts *TLSOptions) (net.Listener, error) {
+	if opts == nil {
+		return listener, nil
+	}
+	cfg, err := certwatcher.NewTLSConfig(certwatcher.Options{
+		CertDir:      opts.CertDir,
+		CertName:     opts.CertName,
+		KeyName:      opts.KeyName,
+		ClientCAName: opts.ClientCAName,
+		TLSOpts:      opts.TLSOpts,
+	}, cm.internalStop)
+	if err != nil {
+		return nil, err
+	}
+	return tls.NewListener(listener, cfg), nil
+}
+
 func (cm *controllerManager) serveHealthProbes(stop <-chan struct{}) {
 	// TODO(hypnoglow): refactor locking to use anonymous func in the similar way
 	// it's done in serveMetrics.
@@ -446,11 +480,21 @@ func (cm *controllerManager) Start(stop <-chan struct{}) error {
 	// (If we don't serve metrics for non-leaders, prometheus will still scrape
 	// the pod but will get a connection refused)
 	if cm.metricsListener != nil {
+		listener, err := cm.secureListener(cm.metricsListener, cm.metricsTLS)
+		if err != nil {
+			return fmt.Errorf("unable to serve metrics over TLS: %w", err)
+		}
+		cm.metricsListener = listener
 		go cm.serveMetrics(cm.internalStop)
 	}
 
 	// Serve health probes
 	if cm.healthProbeListener != nil {
+		listener, err := cm.secureListener(cm.healthProbeListener, cm.healthProbeTLS)
+		if err != nil {
+			return fmt.Errorf("unable to serve health probes over TLS: %w", err)
+		}
+		cm.healthProbeListener = listener
 		go cm.serveHealthProbes(cm.internalStop)
 	}
 
@@ -17,6 +17,7 @@ limitations under the License.
 package manager
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
@@ -106,7 +107,9 @@ type Manager interface {
 	// use case.
 	GetAPIReader() client.Reader
 
-	// GetWebhookServer returns a webhook.Server
+	// GetWebhookServer returns a webhook.Server.  The first call adds the server
+	// to the manager, along with a readiness check named "webhook" which passes
+	// once the server accepts connections.
 	GetWebhookServer() *webhook.Server
 }
 
@@ -164,10 +167,18 @@ type Options struct {
 	// It can be set to "0" to disable the metrics serving.
 	MetricsBindAddress string
 
+	// MetricsTLS, if set, makes the metrics endpoint served over TLS with the
+	// given settings, instead of plain HTTP.
+	MetricsTLS *TLSOptions
+
 	// HealthProbeBindAddress is the TCP address that the controller should bind to
 	// for serving health probes
 	HealthProbeBindAddress string
 
+	// HealthProbeTLS, if set, makes the health probes served over TLS with the
+	// given settings, instead of plain HTTP.
+	HealthProbeTLS *TLSOptions
+
 	// Readiness probe endpoint name, defaults to "readyz"
 	ReadinessEndpointName string
 
@@ -186,6 +197,8 @@ type Options struct {
 	// {TempDir}/k8s-webhook-server/serving-certs. The server key and certificate
 	// must be named tls.key and tls.crt, respectively.
 	CertDir string
+	// TLSOpts is used to set webhook.Server.TLSOpts.
+	TLSOpts []func(*tls.Config)
 	// Functions to all for a user to customize the values that will be injected.
 
 	// NewCache is the function that will create the cache to be used
@@ -212,6 +225,29 @@ type Options struct {
 	newHealthProbeListener func(addr string) (net.Listener, error)
 }
 
+// TLSOptions configures serving over TLS.  The certificates are reloaded
+// whenever they change.
+type TLSOptions struct {
+	// CertDir is the directory that contains the server key and certificate.
+	CertDir string
+
+	// CertName is the server certificate name. Defaults to tls.crt.
+	CertName string
+
+	// KeyName is the server key name. Defaults to tls.key.
+	KeyName string
+
+	// ClientCAName is the CA certificate name which the server uses to verify
+	// client certificates.  Defaults to "", which means client certificates
+	// are not verified.
+	ClientCAName string
+
+	// TLSOpts are functions applied to the TLS config in order, once the
+	// certificates have been configured, for instance to set the minimum TLS
+	// version or the allowed cipher suites.
+	TLSOpts []func(*tls.Config)
+}
+
 // NewClientFunc allows a user to define how to create a client
 type NewClientFunc func(cache cache.Cache, config *rest.Config, options client.Options) (client.Client, error)
 
@@ -327,6 +363,7 @@ func New(config *rest.Config, options Options) (Manager, error) {
 		resourceLock:          resourceLock,
 		mapper:                mapper,
 		metricsListener:       metricsListener,
+		metricsTLS:            options.MetricsTLS,
 		metricsExtraHandlers:  metricsExtraHandlers,
 		internalStop:          stop,
 		internalStopper:       stop,
@@ -334,10 +371,12 @@ func New(config *rest.Config, options Options) (Manager, error) {
 		port:                  options.Port,
 		host:                  options.Host,
 		certDir:               options.CertDir,
+		tlsOpts:               options.TLSOpts,
 		leaseDuration:         *options.LeaseDuration,
 		renewDeadline:         *options.RenewDeadline,
 		retryPeriod:           *options.RetryPeriod,
 		healthProbeListener:   healthProbeListener,
+		healthProbeTLS:        options.HealthProbeTLS,
 		readinessEndpointName: options.ReadinessEndpointName,
 		livenessEndpointName:  options.LivenessEndpointName,
 	}, nil
@@ -99,6 +99,12 @@ func init() {
 	workqueue.SetProvider(workqueueMetricsProvider{})
 }
 
+// WorkqueueMetricsProvider returns the provider of the workqueue metrics registered
+// in Registry, for the queues not created with the client-go workqueue package.
+func WorkqueueMetricsProvider() workqueue.MetricsProvider {
+	return workqueueMetricsProvider{}
+}
+
 type workqueueMetricsProvider struct{}
 
 func (workqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicate
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ Predicate = &fieldChangedPredicate{}
+
+// doubleQuotedKey matches map keys in double quotes, e.g. ["tier"], which the
+// jsonpath package only supports in single quotes.
+var doubleQuotedKey = regexp.MustCompile(`\["([^"\]]*)"\]`)
+
+// FieldChangedPredicate constructs a Predicate which skips update events unless
+// one of the fields selected by the given JSONPath expressions changed.  Create,
+// delete and generic events are not filtered.
+//
+// Expressions use the kubectl JSONPath syntax, with or without the enclosing
+// braces, and refer to the JSON field names of the object, e.g. ".spec.replicas",
+// ".metadata.labels['tier']" or "{.spec.containers[*].image}".  A field missing
+// from both objects is unchanged, including an element of a list selected by index,
+// e.g. ".spec.containers[1].image", while a field or an element found on only one
+// of the objects changed.  Both typed and unstructured objects are supported.
+func FieldChangedPredicate(paths ...string) (Predicate, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("at least one JSONPath expression is required")
+	}
+	p := &fieldChangedPredicate{}
+	for _, path := range paths {
+		expr := doubleQuotedKey.ReplaceAllString(strings.TrimSpace(path), "['$1']")
+		if !strings.HasPrefix(expr, "{") {
+			expr = "{" + expr + "}"
+		}
+		jp := jsonpath.New(path).AllowMissingKeys(true)
+		if err := jp.Parse(expr); err != nil {
+			return nil, fmt.Errorf("invalid JSONPath expression %q: %w", path, err)
+		}
+		p.paths = append(p.paths, jp)
+	}
+	return p, nil
+}
+
+// fieldChangedPredicate implements FieldChangedPredicate.
+type fieldChangedPredicate struct {
+	Funcs
+
+	// mu guards paths, since evaluating a JSONPath isn't thread-safe
+	mu    sync.Mutex
+	paths []*jsonpath.JSONPath
+}
+
+// Update implements Predicate
+func (p *fieldChangedPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil {
+		log.Error(nil, "Update event has no old runtime object to update", "event", e)
+		return false
+	}
+	if e.ObjectNew == nil {
+		log.Error(nil, "Update event has no new runtime object for update", "event", e)
+		return false
+	}
+
+	oldContent, err := toUnstructuredContent(e.ObjectOld)
+	if err != nil {
+		log.Error(err, "unable to convert the old object of an update event", "event", e)
+		return false
+	}
+	newContent, err := toUnstructuredContent(e.ObjectNew)
+	if err != nil {
+		log.Error(err, "unable to convert the new object of an update event", "event", e)
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, path := range p.paths {
+		// an indexed path fails to evaluate on an object missing the element, which
+		// is then like a missing field: it only changed if found on the other object
+		oldValues, oldErr := findValues(path, oldContent)
+		newValues, newErr := findValues(path, newContent)
+		if oldErr != nil && newErr != nil {
+			log.Error(newErr, "unable to evaluate JSONPath on the objects of an update event", "path", path)
+			continue
+		}
+		if !reflect.DeepEqual(oldValues, newValues) {
+			return true
+		}
+	}
+	return false
+}
+
+// toUnstructuredContent returns the JSON representation of obj as a map.
+func toUnstructuredContent(obj runtime.Object) (map[string]interface{}, error) {
+	if u, ok := obj.(runtime.Unstructured); ok {
+		return u.UnstructuredContent(), nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
+// findValues returns the values selected by path in content.
+func findValues(path *jsonpath.JSONPath, content map[string]interface{}) ([]interface{}, error) {
+	results, err := path.FindResults(content)
+	if err != nil {
+		return nil, err
+	}
+	var values []interface{}
+	for _, result := range results {
+		for _, v := range result {
+			values = append(values, v.Interface())
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicate_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+var _ = Describe("FieldChangedPredicate", func() {
+	var deploy *appsv1.Deployment
+
+	BeforeEach(func() {
+		replicas := int32(2)
+		deploy = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "biz",
+				Name:      "baz",
+				Labels:    map[string]string{"tier": "web", "team": "a"},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "c", Image: "nginx:1"}},
+				}},
+			},
+		}
+	})
+
+	update := func(old, new *appsv1.Deployment) event.UpdateEvent {
+		return event.UpdateEvent{MetaOld: old, ObjectOld: old, MetaNew: new, ObjectNew: new}
+	}
+
+	It("should not filter create, delete and generic events", func() {
+		instance, err := predicate.FieldChangedPredicate(".spec.replicas")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instance.Create(event.CreateEvent{Meta: deploy, Object: deploy})).To(BeTrue())
+		Expect(instance.Delete(event.DeleteEvent{Meta: deploy, Object: deploy})).To(BeTrue())
+		Expect(instance.Generic(event.GenericEvent{Meta: deploy, Object: deploy})).To(BeTrue())
+	})
+
+	It("should only pass updates of the selected fields of typed objects", func() {
+		instance, err := predicate.FieldChangedPredicate(".spec.replicas", `.metadata.labels["tier"]`, "{.spec.template.spec.containers[*].image}")
+		Expect(err).NotTo(HaveOccurred())
+
+		unchanged := deploy.DeepCopy()
+		unchanged.Labels["team"] = "b"
+		unchanged.Annotations = map[string]string{"foo": "bar"}
+		Expect(instance.Update(update(deploy, unchanged))).To(BeFalse())
+
+		scaled := deploy.DeepCopy()
+		*scaled.Spec.Replicas = 3
+		Expect(instance.Update(update(deploy, scaled))).To(BeTrue())
+
+		retiered := deploy.DeepCopy()
+		retiered.Labels["tier"] = "db"
+		Expect(instance.Update(update(deploy, retiered))).To(BeTrue())
+
+		untiered := deploy.DeepCopy()
+		delete(untiered.Labels, "tier")
+		Expect(instance.Update(update(deploy, untiered))).To(BeTrue())
+
+		upgraded := deploy.DeepCopy()
+		upgraded.Spec.Template.Spec.Containers[0].Image = "nginx:2"
+		Expect(instance.Update(update(deploy, upgraded))).To(BeTrue())
+	})
+
+	It("should treat fields missing from both objects as unchanged", func() {
+		instance, err := predicate.FieldChangedPredicate(".spec.paused", ".metadata.labels.missing")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instance.Update(update(deploy, deploy.DeepCopy()))).To(BeFalse())
+
+		paused := deploy.DeepCopy()
+		paused.Spec.Paused = true
+		Expect(instance.Update(update(deploy, paused))).To(BeTrue())
+	})
+
+	It("should treat an element appearing or disappearing at an indexed path as changed", func() {
+		instance, err := predicate.FieldChangedPredicate(".spec.template.spec.containers[1].image")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instance.Update(update(deploy, deploy.DeepCopy()))).To(BeFalse())
+
+		sidecar := deploy.DeepCopy()
+		sidecar.Spec.Template.Spec.Containers = append(sidecar.Spec.Template.Spec.Containers, corev1.Container{Name: "s", Image: "envoy:1"})
+		Expect(instance.Update(update(deploy, sidecar))).To(BeTrue())
+		Expect(instance.Update(update(sidecar, deploy))).To(BeTrue())
+		Expect(instance.Update(update(sidecar, sidecar.DeepCopy()))).To(BeFalse())
+	})
+
+	It("should support unstructured objects", func() {
+		instance, err := predicate.FieldChangedPredicate(".spec.replicas")
+		Expect(err).NotTo(HaveOccurred())
+
+		old := &unstructured.Unstructured{}
+		old.SetUnstructuredContent(map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "Foo",
+			"metadata":   map[string]interface{}{"name": "baz", "namespace": "biz"},
+			"spec":       map[string]interface{}{"replicas": int64(2), "other": "a"},
+		})
+		new := old.DeepCopy()
+		Expect(unstructured.SetNestedField(new.Object, "b", "spec", "other")).To(Succeed())
+		Expect(instance.Update(event.UpdateEvent{MetaOld: old, ObjectOld: old, MetaNew: new, ObjectNew: new})).To(BeFalse())
+
+		Expect(unstructured.SetNestedField(new.Object, int64(3), "spec", "replicas")).To(Succeed())
+		Expect(instance.Update(event.UpdateEvent{MetaOld: old, ObjectOld: old, MetaNew: new, ObjectNew: new})).To(BeTrue())
+	})
+
+	It("should return false if the objects are missing", func() {
+		instance, err := predicate.FieldChangedPredicate(".spec.replicas")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instance.Update(event.UpdateEvent{MetaOld: deploy, ObjectOld: deploy, MetaNew: deploy})).To(BeFalse())
+		Expect(instance.Update(event.UpdateEvent{MetaOld: deploy, MetaNew: deploy, ObjectNew: deploy})).To(BeFalse())
+	})
+
+	It("should return an error for invalid or missing expressions", func() {
+		_, err := predicate.FieldChangedPredicate(".spec.containers[")
+		Expect(err).To(HaveOccurred())
+		_, err = predicate.FieldChangedPredicate()
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -21,8 +21,10 @@ import (
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
 )
 
 var _ = Describe("Predicate", func() {
@@ -438,4 +440,186 @@ var _ = Describe("Predicate", func() {
 		})
 
 	})
+
+	Describe("NewPredicateFuncs with a namespace filter", func() {
+		instance := predicate.NewPredicateFuncs(func(meta metav1.Object, object runtime.Object) bool {
+			return meta.GetNamespace() == "biz"
+		})
+
+		It("should filter all kinds of events", func() {
+			other := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "bar", Name: "baz"}}
+			Expect(instance.Create(event.CreateEvent{Meta: pod, Object: pod})).To(BeTrue())
+			Expect(instance.Create(event.CreateEvent{Meta: other, Object: other})).To(BeFalse())
+			Expect(instance.Delete(event.DeleteEvent{Meta: pod, Object: pod})).To(BeTrue())
+			Expect(instance.Delete(event.DeleteEvent{Meta: other, Object: other})).To(BeFalse())
+			Expect(instance.Generic(event.GenericEvent{Meta: pod, Object: pod})).To(BeTrue())
+			Expect(instance.Generic(event.GenericEvent{Meta: other, Object: other})).To(BeFalse())
+		})
+
+		It("should filter update events on the new object", func() {
+			other := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "bar", Name: "baz"}}
+			Expect(instance.Update(event.UpdateEvent{MetaOld: other, ObjectOld: other, MetaNew: pod, ObjectNew: pod})).To(BeTrue())
+			Expect(instance.Update(event.UpdateEvent{MetaOld: pod, ObjectOld: pod, MetaNew: other, ObjectNew: other})).To(BeFalse())
+		})
+	})
+
+	Describe("When checking a NamespacePredicate", func() {
+		It("should only admit objects in the given namespaces", func() {
+			instance := predicate.NamespacePredicate("foo", "biz")
+			other := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "bar", Name: "baz"}}
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+			Expect(instance.Create(event.CreateEvent{Meta: pod, Object: pod})).To(BeTrue())
+			Expect(instance.Create(event.CreateEvent{Meta: other, Object: other})).To(BeFalse())
+			Expect(instance.Create(event.CreateEvent{Meta: node, Object: node})).To(BeFalse())
+		})
+
+		It("should admit cluster-scoped objects if the empty namespace is given", func() {
+			instance := predicate.NamespacePredicate("")
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+			Expect(instance.Create(event.CreateEvent{Meta: node, Object: node})).To(BeTrue())
+			Expect(instance.Create(event.CreateEvent{Meta: pod, Object: pod})).To(BeFalse())
+		})
+	})
+
+	Describe("When checking a LabelSelectorPredicate", func() {
+		It("should only admit objects matching the selector", func() {
+			instance, err := predicate.LabelSelectorPredicate(metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "foo"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      "tier",
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   []string{"web", "db"},
+				}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			matching := pod.DeepCopy()
+			matching.Labels = map[string]string{"app": "foo", "tier": "web"}
+			wrongTier := pod.DeepCopy()
+			wrongTier.Labels = map[string]string{"app": "foo", "tier": "cache"}
+
+			Expect(instance.Create(event.CreateEvent{Meta: matching, Object: matching})).To(BeTrue())
+			Expect(instance.Create(event.CreateEvent{Meta: wrongTier, Object: wrongTier})).To(BeFalse())
+			Expect(instance.Create(event.CreateEvent{Meta: pod, Object: pod})).To(BeFalse())
+			Expect(instance.Update(event.UpdateEvent{MetaOld: pod, ObjectOld: pod, MetaNew: matching, ObjectNew: matching})).To(BeTrue())
+		})
+
+		It("should return an error for an invalid selector", func() {
+			_, err := predicate.LabelSelectorPredicate(metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Like"}},
+			})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("When checking an AnnotationChangedPredicate", func() {
+		instance := predicate.AnnotationChangedPredicate{}
+
+		It("should return true if an annotation was added, changed or removed", func() {
+			old := pod.DeepCopy()
+			old.Annotations = map[string]string{"foo": "bar"}
+
+			added := old.DeepCopy()
+			added.Annotations["baz"] = "qux"
+			changed := old.DeepCopy()
+			changed.Annotations["foo"] = "baz"
+			removed := old.DeepCopy()
+			removed.Annotations = nil
+
+			for _, new := range []*corev1.Pod{added, changed, removed} {
+				Expect(instance.Update(event.UpdateEvent{MetaOld: old, ObjectOld: old, MetaNew: new, ObjectNew: new})).To(BeTrue())
+			}
+		})
+
+		It("should return false if the annotations are unchanged", func() {
+			old := pod.DeepCopy()
+			old.Annotations = map[string]string{"foo": "bar"}
+			new := old.DeepCopy()
+			new.Labels = map[string]string{"foo": "bar"}
+			Expect(instance.Update(event.UpdateEvent{MetaOld: old, ObjectOld: old, MetaNew: new, ObjectNew: new})).To(BeFalse())
+		})
+
+		It("should return false if the metadata is missing", func() {
+			Expect(instance.Update(event.UpdateEvent{MetaOld: pod, ObjectOld: pod})).To(BeFalse())
+			Expect(instance.Update(event.UpdateEvent{MetaNew: pod, ObjectNew: pod})).To(BeFalse())
+		})
+	})
+
+	Describe("When checking a LabelChangedPredicate", func() {
+		instance := predicate.LabelChangedPredicate{}
+
+		It("should return true if a label was changed", func() {
+			old := pod.DeepCopy()
+			old.Labels = map[string]string{"foo": "bar"}
+			new := old.DeepCopy()
+			new.Labels["foo"] = "baz"
+			Expect(instance.Update(event.UpdateEvent{MetaOld: old, ObjectOld: old, MetaNew: new, ObjectNew: new})).To(BeTrue())
+		})
+
+		It("should return false if the labels are unchanged", func() {
+			old := pod.DeepCopy()
+			old.Labels = map[string]string{"foo": "bar"}
+			new := old.DeepCopy()
+			new.Annotations = map[string]string{"foo": "bar"}
+			Expect(instance.Update(event.UpdateEvent{MetaOld: old, ObjectOld: old, MetaNew: new, ObjectNew: new})).To(BeFalse())
+		})
+	})
+
+	Describe("When checking And, Or and Not predicates", func() {
+		passFuncs := predicate.Funcs{}
+		failFuncs := predicate.Funcs{
+			CreateFunc:  func(event.CreateEvent) bool { return false },
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			UpdateFunc:  func(event.UpdateEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+		}
+
+		expectAll := func(p predicate.Predicate, result bool) {
+			Expect(p.Create(event.CreateEvent{})).To(Equal(result))
+			Expect(p.Update(event.UpdateEvent{})).To(Equal(result))
+			Expect(p.Delete(event.DeleteEvent{})).To(Equal(result))
+			Expect(p.Generic(event.GenericEvent{})).To(Equal(result))
+		}
+
+		It("should return true from And only if all predicates pass", func() {
+			expectAll(predicate.And(passFuncs, passFuncs), true)
+			expectAll(predicate.And(passFuncs, failFuncs), false)
+			expectAll(predicate.And(), true)
+		})
+
+		It("should return true from Or if any predicate passes", func() {
+			expectAll(predicate.Or(failFuncs, passFuncs), true)
+			expectAll(predicate.Or(failFuncs, failFuncs), false)
+			expectAll(predicate.Or(), false)
+		})
+
+		It("should negate the predicate with Not", func() {
+			expectAll(predicate.Not(passFuncs), false)
+			expectAll(predicate.Not(failFuncs), true)
+			expectAll(predicate.Not(predicate.And(passFuncs, failFuncs)), true)
+		})
+
+		It("should inject functions into the predicates they combine", func() {
+			injected := &injectablePredicate{}
+			instance := predicate.Not(predicate.And(passFuncs, predicate.Or(injected)))
+			// mimic the manager's SetFields, which injects into injectors recursively
+			var setFields inject.Func
+			setFields = func(i interface{}) error {
+				_, err := inject.InjectorInto(setFields, i)
+				return err
+			}
+			Expect(setFields(instance)).To(Succeed())
+			Expect(injected.injected).To(BeTrue())
+		})
+	})
 })
+
+type injectablePredicate struct {
+	predicate.Funcs
+	injected bool
+}
+
+func (p *injectablePredicate) InjectFunc(f inject.Func) error {
+	p.injected = true
+	return nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicate
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// TypedPredicate filters events for objects of type T before enqueuing the keys.
+// Use Untyped to use it wherever a Predicate is expected.
+type TypedPredicate[T runtime.Object] interface {
+	// Create returns true if the Create event should be processed
+	Create(event.TypedCreateEvent[T]) bool
+
+	// Delete returns true if the Delete event should be processed
+	Delete(event.TypedDeleteEvent[T]) bool
+
+	// Update returns true if the Update event should be processed
+	Update(event.TypedUpdateEvent[T]) bool
+
+	// Generic returns true if the Generic event should be processed
+	Generic(event.TypedGenericEvent[T]) bool
+}
+
+// TypedFuncs is a function that implements TypedPredicate.
+type TypedFuncs[T runtime.Object] struct {
+	// Create returns true if the Create event should be processed
+	CreateFunc func(event.TypedCreateEvent[T]) bool
+
+	// Delete returns true if the Delete event should be processed
+	DeleteFunc func(event.TypedDeleteEvent[T]) bool
+
+	// Update returns true if the Update event should be processed
+	UpdateFunc func(event.TypedUpdateEvent[T]) bool
+
+	// Generic returns true if the Generic event should be processed
+	GenericFunc func(event.TypedGenericEvent[T]) bool
+}
+
+// Create implements TypedPredicate
+func (p TypedFuncs[T]) Create(e event.TypedCreateEvent[T]) bool {
+	if p.CreateFunc != nil {
+		return p.CreateFunc(e)
+	}
+	return true
+}
+
+// Delete implements TypedPredicate
+func (p TypedFuncs[T]) Delete(e event.TypedDeleteEvent[T]) bool {
+	if p.DeleteFunc != nil {
+		return p.DeleteFunc(e)
+	}
+	return true
+}
+
+// Update implements TypedPredicate
+func (p TypedFuncs[T]) Update(e event.TypedUpdateEvent[T]) bool {
+	if p.UpdateFunc != nil {
+		return p.UpdateFunc(e)
+	}
+	return true
+}
+
+// Generic implements TypedPredicate
+func (p TypedFuncs[T]) Generic(e event.TypedGenericEvent[T]) bool {
+	if p.GenericFunc != nil {
+		return p.GenericFunc(e)
+	}
+	return true
+}
+
+// Untyped adapts a TypedPredicate to a Predicate, so that it can be mixed with
+// other predicates, e.g. in builder.WithEventFilter.  Events for objects that are
+// not of type T are passed through, so that a predicate for one type does not
+// filter out the events of the other types watched by the same controller.
+func Untyped[T runtime.Object](p TypedPredicate[T]) Predicate {
+	return untyped[T]{typed: p}
+}
+
+// untyped implements Predicate on top of a TypedPredicate.
+type untyped[T runtime.Object] struct {
+	typed TypedPredicate[T]
+}
+
+// Create implements Predicate
+func (p untyped[T]) Create(e event.CreateEvent) bool {
+	te, ok := event.ToTypedCreateEvent[T](e)
+	if !ok {
+		return true
+	}
+	return p.typed.Create(te)
+}
+
+// Delete implements Predicate
+func (p untyped[T]) Delete(e event.DeleteEvent) bool {
+	te, ok := event.ToTypedDeleteEvent[T](e)
+	if !ok {
+		return true
+	}
+	return p.typed.Delete(te)
+}
+
+// Update implements Predicate
+func (p untyped[T]) Update(e event.UpdateEvent) bool {
+	te, ok := event.ToTypedUpdateEvent[T](e)
+	if !ok {
+		return true
+	}
+	return p.typed.Update(te)
+}
+
+// Generic implements Predicate
+func (p untyped[T]) Generic(e event.GenericEvent) bool {
+	te, ok := event.ToTypedGenericEvent[T](e)
+	if !ok {
+		return true
+	}
+	return p.typed.Generic(te)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicate_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+var _ = Describe("TypedPredicate", func() {
+	var pod *corev1.Pod
+	BeforeEach(func() {
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "baz"},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+		}
+	})
+
+	onNode := predicate.TypedFuncs[*corev1.Pod]{
+		CreateFunc: func(e event.TypedCreateEvent[*corev1.Pod]) bool {
+			return e.Object.Spec.NodeName == "node-1"
+		},
+		UpdateFunc: func(e event.TypedUpdateEvent[*corev1.Pod]) bool {
+			return e.ObjectOld.Spec.NodeName != e.ObjectNew.Spec.NodeName
+		},
+		DeleteFunc: func(e event.TypedDeleteEvent[*corev1.Pod]) bool {
+			return !e.DeleteStateUnknown
+		},
+	}
+
+	It("should default to processing events", func() {
+		instance := predicate.TypedFuncs[*corev1.Pod]{}
+		Expect(instance.Create(event.TypedCreateEvent[*corev1.Pod]{Object: pod})).To(BeTrue())
+		Expect(instance.Update(event.TypedUpdateEvent[*corev1.Pod]{ObjectOld: pod, ObjectNew: pod})).To(BeTrue())
+		Expect(instance.Delete(event.TypedDeleteEvent[*corev1.Pod]{Object: pod})).To(BeTrue())
+		Expect(instance.Generic(event.TypedGenericEvent[*corev1.Pod]{Object: pod})).To(BeTrue())
+	})
+
+	It("should pass typed objects to the predicate through Untyped", func() {
+		instance := predicate.Untyped[*corev1.Pod](onNode)
+
+		Expect(instance.Create(event.CreateEvent{Meta: pod, Object: pod})).To(BeTrue())
+		other := pod.DeepCopy()
+		other.Spec.NodeName = "node-2"
+		Expect(instance.Create(event.CreateEvent{Meta: other, Object: other})).To(BeFalse())
+
+		Expect(instance.Update(event.UpdateEvent{MetaOld: pod, ObjectOld: pod, MetaNew: other, ObjectNew: other})).To(BeTrue())
+		Expect(instance.Update(event.UpdateEvent{MetaOld: pod, ObjectOld: pod, MetaNew: pod, ObjectNew: pod})).To(BeFalse())
+
+		Expect(instance.Delete(event.DeleteEvent{Meta: pod, Object: pod})).To(BeTrue())
+		Expect(instance.Delete(event.DeleteEvent{Meta: pod, Object: pod, DeleteStateUnknown: true})).To(BeFalse())
+
+		Expect(instance.Generic(event.GenericEvent{Meta: pod, Object: pod})).To(BeTrue())
+	})
+
+	It("should pass through events for objects of other types", func() {
+		instance := predicate.Untyped[*corev1.Pod](predicate.TypedFuncs[*corev1.Pod]{
+			CreateFunc: func(event.TypedCreateEvent[*corev1.Pod]) bool {
+				defer GinkgoRecover()
+				Fail("Did not expect CreateFunc to be called.")
+				return false
+			},
+		})
+		deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "baz"}}
+		Expect(instance.Create(event.CreateEvent{Meta: deploy, Object: deploy})).To(BeTrue())
+	})
+})
@@ -0,0 +1,551 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciletest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/testing"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	defaultMaxReconciles = 100
+
+	// deliveryTimeout is how long RunUntilIdle waits for the informers of the fake
+	// cache to deliver the events of the writes made so far.
+	deliveryTimeout = 10 * time.Second
+)
+
+// HarnessOptions are the optional arguments for creating a Harness.
+type HarnessOptions struct {
+	// Scheme is the scheme used to map objects to kinds.
+	// Defaults to the Kubernetes client-go scheme.
+	Scheme *runtime.Scheme
+
+	// Client is the client given to the watches and, through it, the source of
+	// events.  It must have been created by the client/fake package.
+	// Defaults to a fake client seeded with InitObjs.
+	Client client.Client
+
+	// InitObjs are the objects the default Client is created with.
+	InitObjs []runtime.Object
+
+	// Mapper is the RESTMapper given to the watches.
+	// Defaults to a mapper that considers every kind of Scheme namespaced.
+	Mapper meta.RESTMapper
+
+	// Clock is the clock that delayed requeues are measured against.
+	// Defaults to a fake clock set to the current time.
+	Clock *clock.FakeClock
+
+	// RateLimiter is used to delay requeues after errors or Requeue results.
+	// Defaults to a per-item exponential rate limiter, which unlike the
+	// default controller rate limiter does not depend on the real time.
+	RateLimiter ratelimiter.RateLimiter
+
+	// MaxReconciles is the number of reconciles after which RunUntilIdle gives up,
+	// to avoid hanging on reconcilers that never settle.  Defaults to 100.
+	MaxReconciles int
+}
+
+// Reconciliation records a single call made by the harness to the reconciler.
+type Reconciliation struct {
+	// Request is the request the reconciler was called with.
+	Request reconcile.Request
+
+	// Result is the result the reconciler returned.
+	Result reconcile.Result
+
+	// Err is the error the reconciler returned.
+	Err error
+
+	// Time is the time of the harness' clock when the reconciler was called.
+	Time time.Time
+}
+
+var _ controller.Controller = &Harness{}
+
+// Harness drives a reconciler through the watches of a controller
+// deterministically.
+//
+// Watches are registered on the harness like on a controller.Controller, and
+// Kind sources are wired to a fake cache built with informertest.NewFakeCache.
+// Writes made through the harness' client, by the test or by the reconciler,
+// are turned into Create, Update and Delete events for those watches, which
+// pass through their predicates and event handlers into the harness' queue.
+// RunUntilIdle waits for the events of every write made so far to be delivered,
+// and then reconciles the queued requests one at a time until nothing is left
+// to do.
+//
+// Tests push events by writing objects through Client, and requests with Enqueue.
+// Call Stop once done with the harness, to stop its fake cache.
+//
+// Requests requeued with a delay only become ready once the harness' clock
+// has been advanced past that delay.
+type Harness struct {
+	// Clock is the clock delayed requeues are measured against.  Advance it to
+	// make requests requeued with RequeueAfter ready again.
+	Clock *clock.FakeClock
+
+	// Client is the client events are derived from.  It should be given to the
+	// reconciler.
+	Client client.Client
+
+	// Scheme is the scheme used to map objects to kinds.
+	Scheme *runtime.Scheme
+
+	reconciler    reconcile.Reconciler
+	mapper        meta.RESTMapper
+	tracker       testing.ObjectTracker
+	cache         cache.Cache
+	queue         *clockQueue
+	maxReconciles int
+	stop          chan struct{}
+	stopOnce      sync.Once
+
+	// mu guards the fields below
+	mu sync.Mutex
+
+	reconciles []Reconciliation
+
+	// handlersLock guards handlers.  It is separate from mu, as handlers are
+	// added while Watch holds mu.
+	handlersLock sync.Mutex
+
+	// handlers are the event handlers added to the informers of the cache
+	handlers []*trackingHandler
+}
+
+// NewHarness returns a Harness for the given reconciler.
+func NewHarness(r reconcile.Reconciler, opts HarnessOptions) (*Harness, error) {
+	if r == nil {
+		return nil, fmt.Errorf("must specify Reconciler")
+	}
+	if opts.Scheme == nil {
+		opts.Scheme = scheme.Scheme
+	}
+	if opts.Client == nil {
+		opts.Client = fake.NewFakeClientWithScheme(opts.Scheme, opts.InitObjs...)
+	}
+	tracker, err := fake.ObjectTrackerFor(opts.Client)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Mapper == nil {
+		opts.Mapper = namespacedMapperFor(opts.Scheme)
+	}
+	if opts.Clock == nil {
+		opts.Clock = clock.NewFakeClock(time.Now())
+	}
+	if opts.RateLimiter == nil {
+		opts.RateLimiter = workqueue.NewItemExponentialFailureRateLimiter(5*time.Millisecond, 1000*time.Second)
+	}
+	if opts.MaxReconciles <= 0 {
+		opts.MaxReconciles = defaultMaxReconciles
+	}
+
+	c, err := informertest.NewFakeCache(opts.Scheme, opts.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &Harness{
+		Clock:         opts.Clock,
+		Client:        opts.Client,
+		Scheme:        opts.Scheme,
+		reconciler:    r,
+		mapper:        opts.Mapper,
+		tracker:       tracker,
+		queue:         newClockQueue(opts.Clock, opts.RateLimiter),
+		maxReconciles: opts.MaxReconciles,
+		stop:          make(chan struct{}),
+	}
+	h.cache = &trackingCache{Cache: c, harness: h}
+
+	// Start the cache right away, so that the informers of Kind sources run
+	// as soon as they are watched.
+	go c.Start(h.stop) // nolint:errcheck
+	if !c.WaitForCacheSync(h.stop) {
+		h.Stop()
+		return nil, fmt.Errorf("unable to start the fake cache")
+	}
+	return h, nil
+}
+
+// namespacedMapperFor returns a RESTMapper knowing every kind of the given
+// scheme as a namespaced kind.
+func namespacedMapperFor(s *runtime.Scheme) meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(s.PrioritizedVersionsAllGroups())
+	for gvk := range s.AllKnownTypes() {
+		mapper.Add(gvk, meta.RESTScopeNamespace)
+	}
+	return mapper
+}
+
+// Reconcile implements reconcile.Reconciler by calling the reconciler
+// directly, bypassing the queue.
+func (h *Harness) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+	return h.reconciler.Reconcile(req)
+}
+
+// Watch implements controller.Controller.  Kind sources are wired to the
+// harness' fake cache; other sources are started as they would be by a
+// controller.
+func (h *Harness) Watch(src source.Source, evthdler handler.EventHandler, prct ...predicate.Predicate) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.setFields(src); err != nil {
+		return err
+	}
+	if err := h.setFields(evthdler); err != nil {
+		return err
+	}
+	for _, pr := range prct {
+		if err := h.setFields(pr); err != nil {
+			return err
+		}
+	}
+	return src.Start(evthdler, h.queue, prct...)
+}
+
+// Start implements controller.Controller.  The harness does not run any
+// workers, requests are processed by RunUntilIdle; Start only blocks until
+// stop is closed, and then stops the harness like Stop.
+func (h *Harness) Start(stop <-chan struct{}) error {
+	<-stop
+	h.Stop()
+	return nil
+}
+
+// Stop stops the cache, the sources that were given a stop channel and the queue.
+// It may be called several times.
+func (h *Harness) Stop() {
+	h.stopOnce.Do(func() {
+		close(h.stop)
+		h.queue.ShutDown()
+	})
+}
+
+// setFields injects the harness' dependencies into watch arguments.
+func (h *Harness) setFields(i interface{}) error {
+	if _, err := inject.CacheInto(h.cache, i); err != nil {
+		return err
+	}
+	if _, err := inject.ClientInto(h.Client, i); err != nil {
+		return err
+	}
+	if _, err := inject.SchemeInto(h.Scheme, i); err != nil {
+		return err
+	}
+	if _, err := inject.MapperInto(h.mapper, i); err != nil {
+		return err
+	}
+	if _, err := inject.StopChannelInto(h.stop, i); err != nil {
+		return err
+	}
+	if _, err := inject.InjectorInto(h.setFields, i); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Enqueue adds a request to the queue, as an event handler would.
+func (h *Harness) Enqueue(req reconcile.Request) {
+	h.queue.Add(req)
+}
+
+// Reconciles returns every call made to the reconciler by RunUntilIdle so far, in order.
+func (h *Harness) Reconciles() []Reconciliation {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]Reconciliation(nil), h.reconciles...)
+}
+
+// Requests returns the requests of every call made to the reconciler by
+// RunUntilIdle so far, in order.
+func (h *Harness) Requests() []reconcile.Request {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	reqs := make([]reconcile.Request, 0, len(h.reconciles))
+	for _, r := range h.reconciles {
+		reqs = append(reqs, r.Request)
+	}
+	return reqs
+}
+
+// Reset forgets the calls recorded so far.
+func (h *Harness) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.reconciles = nil
+}
+
+// RunUntilIdle delivers the pending events to the watches and reconciles the
+// requests they enqueue, one at a time, until the queue holds no request that
+// is ready.  Requests requeued with a delay are left in the queue until the
+// clock is advanced.
+//
+// It returns an error if more than MaxReconciles requests were reconciled,
+// which usually means the reconciler keeps changing the objects it watches.
+func (h *Harness) RunUntilIdle() error {
+	for count := 0; ; count++ {
+		if err := h.deliverEvents(); err != nil {
+			return err
+		}
+		item, shutdown := h.queue.Get()
+		if shutdown || item == nil {
+			return nil
+		}
+		if count >= h.maxReconciles {
+			h.queue.Add(item)
+			return fmt.Errorf("reconciler did not settle after %d reconciles", h.maxReconciles)
+		}
+		h.reconcileHandler(item)
+	}
+}
+
+// reconcileHandler reconciles a single item off the queue and requeues it the
+// same way a controller would.
+func (h *Harness) reconcileHandler(obj interface{}) {
+	defer h.queue.Done(obj)
+
+	req, ok := obj.(reconcile.Request)
+	if !ok {
+		h.queue.Forget(obj)
+		return
+	}
+
+	now := h.Clock.Now()
+	result, err := h.reconciler.Reconcile(req)
+
+	h.mu.Lock()
+	h.reconciles = append(h.reconciles, Reconciliation{Request: req, Result: result, Err: err, Time: now})
+	h.mu.Unlock()
+
+	switch {
+	case err != nil:
+		h.queue.AddRateLimited(req)
+	case result.RequeueAfter > 0:
+		h.queue.Forget(obj)
+		h.queue.AddAfter(req, result.RequeueAfter)
+	case result.Requeue:
+		h.queue.AddRateLimited(req)
+	default:
+		h.queue.Forget(obj)
+	}
+}
+
+// deliverEvents waits for the informers of the cache to deliver the events of
+// every write made to the tracker so far to the event handlers of the watches.
+func (h *Harness) deliverEvents() error {
+	h.handlersLock.Lock()
+	handlers := append([]*trackingHandler(nil), h.handlers...)
+	h.handlersLock.Unlock()
+
+	deadline := time.Now().Add(deliveryTimeout)
+	for _, t := range handlers {
+		for {
+			delivered, err := h.delivered(t)
+			if err != nil {
+				return err
+			}
+			if delivered {
+				break
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("timed out waiting for the events of %v to be delivered", t.gvk)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	return nil
+}
+
+// delivered returns whether the given handler was given the current version
+// of every object of its kind in the tracker.
+func (h *Harness) delivered(t *trackingHandler) (bool, error) {
+	// This mirrors the resource guessing done by the fake client, so that
+	// we list the same resource its writes are recorded under.
+	gvr, _ := meta.UnsafeGuessKindToResource(t.gvk)
+	list, err := h.tracker.List(gvr, t.gvk, metav1.NamespaceAll)
+	if err != nil {
+		return false, err
+	}
+	objs, err := meta.ExtractList(list)
+	if err != nil {
+		return false, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(objs) != len(t.versions) {
+		return false, nil
+	}
+	for _, obj := range objs {
+		key, version, err := versionOf(obj)
+		if err != nil {
+			return false, err
+		}
+		if v, ok := t.versions[key]; !ok || v != version {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// versionOf returns the key and the resource version of an object.
+func versionOf(obj interface{}) (string, string, error) {
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return "", "", err
+	}
+	key, err := toolscache.MetaNamespaceKeyFunc(objMeta)
+	if err != nil {
+		return "", "", err
+	}
+	return key, objMeta.GetResourceVersion(), nil
+}
+
+// addHandler records a handler added to an informer of the cache.
+func (h *Harness) addHandler(t *trackingHandler) {
+	h.handlersLock.Lock()
+	defer h.handlersLock.Unlock()
+	h.handlers = append(h.handlers, t)
+}
+
+// trackingCache is a cache whose informers record the objects their event
+// handlers were given.
+type trackingCache struct {
+	cache.Cache
+	harness *Harness
+}
+
+// GetInformer implements cache.Informers
+func (c *trackingCache) GetInformer(ctx context.Context, obj runtime.Object) (cache.Informer, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.harness.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetInformerForKind(ctx, gvk)
+}
+
+// GetInformerForKind implements cache.Informers
+func (c *trackingCache) GetInformerForKind(ctx context.Context, gvk schema.GroupVersionKind) (cache.Informer, error) {
+	i, err := c.Cache.GetInformerForKind(ctx, gvk)
+	if err != nil {
+		return nil, err
+	}
+	return &trackingInformer{Informer: i, gvk: gvk, harness: c.harness}, nil
+}
+
+// trackingInformer is an informer whose event handlers record the objects they were given.
+type trackingInformer struct {
+	cache.Informer
+	gvk     schema.GroupVersionKind
+	harness *Harness
+}
+
+// AddEventHandler implements cache.Informer
+func (i *trackingInformer) AddEventHandler(handler toolscache.ResourceEventHandler) {
+	i.Informer.AddEventHandler(i.track(handler))
+}
+
+// AddEventHandlerWithResyncPeriod implements cache.Informer
+func (i *trackingInformer) AddEventHandlerWithResyncPeriod(handler toolscache.ResourceEventHandler, resyncPeriod time.Duration) {
+	i.Informer.AddEventHandlerWithResyncPeriod(i.track(handler), resyncPeriod)
+}
+
+func (i *trackingInformer) track(handler toolscache.ResourceEventHandler) *trackingHandler {
+	t := &trackingHandler{handler: handler, gvk: i.gvk, versions: map[string]string{}}
+	i.harness.addHandler(t)
+	return t
+}
+
+// trackingHandler records the version of the objects it passes to an event
+// handler, once the handler returned.
+type trackingHandler struct {
+	handler toolscache.ResourceEventHandler
+	gvk     schema.GroupVersionKind
+
+	// mu guards versions
+	mu sync.Mutex
+
+	// versions are the resource versions of the objects delivered, by key
+	versions map[string]string
+}
+
+// OnAdd implements cache.ResourceEventHandler
+func (t *trackingHandler) OnAdd(obj interface{}) {
+	t.handler.OnAdd(obj)
+	t.record(obj)
+}
+
+// OnUpdate implements cache.ResourceEventHandler
+func (t *trackingHandler) OnUpdate(oldObj, newObj interface{}) {
+	t.handler.OnUpdate(oldObj, newObj)
+	t.record(newObj)
+}
+
+// OnDelete implements cache.ResourceEventHandler
+func (t *trackingHandler) OnDelete(obj interface{}) {
+	t.handler.OnDelete(obj)
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	key, _, err := versionOf(obj)
+	if err != nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.versions, key)
+}
+
+func (t *trackingHandler) record(obj interface{}) {
+	key, version, err := versionOf(obj)
+	if err != nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.versions[key] = version
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciletest_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile/reconciletest"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// secretCopier creates a Secret owned by each ConfigMap it reconciles.
+type secretCopier struct {
+	client       client.Client
+	requeueAfter time.Duration
+}
+
+func (r *secretCopier) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+	ctx := context.Background()
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(ctx, req.NamespacedName, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: cm.Name, Namespace: cm.Namespace}}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.client, secret, func() error {
+		secret.StringData = cm.Data
+		return controllerutil.SetControllerReference(cm, secret, scheme.Scheme)
+	})
+	return reconcile.Result{RequeueAfter: r.requeueAfter}, err
+}
+
+var _ = Describe("Harness", func() {
+	var (
+		h   *reconciletest.Harness
+		r   *secretCopier
+		ctx = context.Background()
+		req = reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "cm"}}
+	)
+
+	BeforeEach(func() {
+		r = &secretCopier{}
+		var err error
+		h, err = reconciletest.NewHarness(r, reconciletest.HarnessOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		r.client = h.Client
+
+		Expect(h.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestForObject{})).To(Succeed())
+		Expect(h.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForOwner{
+			OwnerType:    &corev1.ConfigMap{},
+			IsController: true,
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		h.Stop()
+	})
+
+	It("should require a reconciler", func() {
+		_, err := reconciletest.NewHarness(nil, reconciletest.HarnessOptions{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reconcile requests enqueued by writes until the queue is empty", func() {
+		Expect(h.Client.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"},
+			Data:       map[string]string{"a": "b"},
+		})).To(Succeed())
+		Expect(h.RunUntilIdle()).To(Succeed())
+
+		By("reconciling the ConfigMap, then again for the Secret it created")
+		Expect(h.Requests()).To(Equal([]reconcile.Request{req, req}))
+
+		secret := &corev1.Secret{}
+		Expect(h.Client.Get(ctx, req.NamespacedName, secret)).To(Succeed())
+		Expect(secret.StringData).To(Equal(map[string]string{"a": "b"}))
+
+		By("doing nothing once idle")
+		h.Reset()
+		Expect(h.RunUntilIdle()).To(Succeed())
+		Expect(h.Requests()).To(BeEmpty())
+	})
+
+	It("should deliver events for objects existing before the first run", func() {
+		h, err := reconciletest.NewHarness(r, reconciletest.HarnessOptions{
+			InitObjs: []runtime.Object{&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"}}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		defer h.Stop()
+		r.client = h.Client
+		Expect(h.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestForObject{})).To(Succeed())
+
+		Expect(h.RunUntilIdle()).To(Succeed())
+		Expect(h.Requests()).To(Equal([]reconcile.Request{req}))
+	})
+
+	It("should apply predicates to the events", func() {
+		h, err := reconciletest.NewHarness(r, reconciletest.HarnessOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		defer h.Stop()
+		r.client = h.Client
+		Expect(h.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestForObject{},
+			predicate.Funcs{CreateFunc: func(e event.CreateEvent) bool { return e.Meta.GetName() != "ignored" }},
+		)).To(Succeed())
+
+		for _, name := range []string{"ignored", "cm"} {
+			Expect(h.Client.Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}})).To(Succeed())
+		}
+		Expect(h.RunUntilIdle()).To(Succeed())
+		Expect(h.Requests()).To(Equal([]reconcile.Request{req}))
+	})
+
+	It("should deliver delete events", func() {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"}}
+		Expect(h.Client.Create(ctx, cm)).To(Succeed())
+		Expect(h.RunUntilIdle()).To(Succeed())
+		h.Reset()
+
+		Expect(h.Client.Delete(ctx, cm)).To(Succeed())
+		Expect(h.RunUntilIdle()).To(Succeed())
+		Expect(h.Requests()).To(Equal([]reconcile.Request{req}))
+	})
+
+	It("should shut down its queue once started and stopped", func() {
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			Expect(h.Start(stop)).To(Succeed())
+			close(done)
+		}()
+		close(stop)
+		Eventually(done).Should(BeClosed())
+
+		h.Enqueue(req)
+		Expect(h.RunUntilIdle()).To(Succeed())
+		Expect(h.Requests()).To(BeEmpty())
+	})
+
+	It("should shut down its queue once stopped", func() {
+		h.Stop()
+		h.Stop()
+
+		h.Enqueue(req)
+		Expect(h.RunUntilIdle()).To(Succeed())
+		Expect(h.Requests()).To(BeEmpty())
+	})
+
+	It("should only honor RequeueAfter once the clock was advanced", func() {
+		r.requeueAfter = time.Minute
+		Expect(h.Client.Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"}})).To(Succeed())
+		Expect(h.RunUntilIdle()).To(Succeed())
+		Expect(h.Reconciles()).To(HaveLen(2))
+		Expect(h.Reconciles()[1].Result).To(Equal(reconcile.Result{RequeueAfter: time.Minute}))
+
+		h.Clock.Step(59 * time.Second)
+		Expect(h.RunUntilIdle()).To(Succeed())
+		Expect(h.Reconciles()).To(HaveLen(2))
+
+		h.Clock.Step(time.Second)
+		Expect(h.RunUntilIdle()).To(Succeed())
+		Expect(h.Reconciles()).To(HaveLen(3))
+		Expect(h.Reconciles()[2].Time).To(Equal(h.Reconciles()[0].Time.Add(time.Minute)))
+	})
+
+	It("should requeue requests that failed with a backoff", func() {
+		h, err := reconciletest.NewHarness(reconcile.Func(func(reconcile.Request) (reconcile.Result, error) {
+			return reconcile.Result{}, fmt.Errorf("expected error")
+		}), reconciletest.HarnessOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		defer h.Stop()
+
+		h.Enqueue(req)
+		Expect(h.RunUntilIdle()).To(Succeed())
+		Expect(h.Reconciles()).To(HaveLen(1))
+		Expect(h.Reconciles()[0].Err).To(MatchError("expected error"))
+
+		h.Clock.Step(5 * time.Millisecond)
+		Expect(h.RunUntilIdle()).To(Succeed())
+		Expect(h.Reconciles()).To(HaveLen(2))
+	})
+
+	It("should give up on reconcilers that never settle", func() {
+		var h *reconciletest.Harness
+		h, err := reconciletest.NewHarness(reconcile.Func(func(req reconcile.Request) (reconcile.Result, error) {
+			// keep changing the object this reconciler watches
+			cm := &corev1.ConfigMap{}
+			if err := h.Client.Get(ctx, req.NamespacedName, cm); err != nil {
+				return reconcile.Result{}, err
+			}
+			cm.Labels = map[string]string{"resourceVersion": cm.ResourceVersion}
+			return reconcile.Result{}, h.Client.Update(ctx, cm)
+		}), reconciletest.HarnessOptions{MaxReconciles: 3})
+		Expect(err).NotTo(HaveOccurred())
+		defer h.Stop()
+		Expect(h.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestForObject{})).To(Succeed())
+
+		Expect(h.Client.Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"}})).To(Succeed())
+		Expect(h.RunUntilIdle()).To(MatchError("reconciler did not settle after 3 reconciles"))
+		Expect(h.Reconciles()).To(HaveLen(3))
+	})
+})
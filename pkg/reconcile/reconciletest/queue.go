@@ -0,0 +1,185 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciletest
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
+)
+
+var _ workqueue.RateLimitingInterface = &clockQueue{}
+
+// clockQueue is a rate limiting queue whose delays are measured against a
+// (usually fake) clock, and which never blocks.  Items are handed out in the
+// order in which they became ready.
+type clockQueue struct {
+	clock       clock.Clock
+	rateLimiter ratelimiter.RateLimiter
+
+	// mu guards the fields below
+	mu sync.Mutex
+
+	// ready holds the items that can be processed, in order
+	ready []interface{}
+
+	// queued is the set of items in ready, used to de-duplicate them
+	queued map[interface{}]bool
+
+	// waiting holds items that become ready at some point in the future
+	waiting []waitingItem
+
+	shuttingDown bool
+}
+
+// waitingItem is an item that is added to the queue once the clock reaches readyAt.
+type waitingItem struct {
+	item    interface{}
+	readyAt time.Time
+}
+
+func newClockQueue(c clock.Clock, rateLimiter ratelimiter.RateLimiter) *clockQueue {
+	return &clockQueue{
+		clock:       c,
+		rateLimiter: rateLimiter,
+		queued:      map[interface{}]bool{},
+	}
+}
+
+// Add implements workqueue.Interface
+func (q *clockQueue) Add(item interface{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.addLocked(item)
+}
+
+func (q *clockQueue) addLocked(item interface{}) {
+	if q.shuttingDown || q.queued[item] {
+		return
+	}
+	q.queued[item] = true
+	q.ready = append(q.ready, item)
+}
+
+// Len implements workqueue.Interface.  It returns the number of items that
+// are ready to be processed.
+func (q *clockQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.promoteLocked()
+	return len(q.ready)
+}
+
+// Get implements workqueue.Interface.  Unlike the standard queues, it does not
+// block: if no item is ready, it returns a nil item.
+func (q *clockQueue) Get() (item interface{}, shutdown bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.shuttingDown {
+		return nil, true
+	}
+
+	q.promoteLocked()
+	if len(q.ready) == 0 {
+		return nil, false
+	}
+	item = q.ready[0]
+	q.ready = q.ready[1:]
+	delete(q.queued, item)
+	return item, false
+}
+
+// Done implements workqueue.Interface.  Items are processed synchronously, so
+// there is nothing to do.
+func (q *clockQueue) Done(item interface{}) {}
+
+// ShutDown implements workqueue.Interface
+func (q *clockQueue) ShutDown() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.shuttingDown = true
+}
+
+// ShuttingDown implements workqueue.Interface
+func (q *clockQueue) ShuttingDown() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.shuttingDown
+}
+
+// AddAfter implements workqueue.DelayingInterface
+func (q *clockQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if duration <= 0 {
+		q.addLocked(item)
+		return
+	}
+
+	readyAt := q.clock.Now().Add(duration)
+	for i, w := range q.waiting {
+		if w.item == item {
+			// keep the earliest time an item was asked for, like the standard queue does
+			if readyAt.Before(w.readyAt) {
+				q.waiting[i].readyAt = readyAt
+			}
+			return
+		}
+	}
+	q.waiting = append(q.waiting, waitingItem{item: item, readyAt: readyAt})
+}
+
+// AddRateLimited implements workqueue.RateLimitingInterface
+func (q *clockQueue) AddRateLimited(item interface{}) {
+	q.AddAfter(item, q.rateLimiter.When(item))
+}
+
+// Forget implements workqueue.RateLimitingInterface
+func (q *clockQueue) Forget(item interface{}) {
+	q.rateLimiter.Forget(item)
+}
+
+// NumRequeues implements workqueue.RateLimitingInterface
+func (q *clockQueue) NumRequeues(item interface{}) int {
+	return q.rateLimiter.NumRequeues(item)
+}
+
+// promoteLocked moves the waiting items whose time has come to the ready
+// list, earliest first.
+func (q *clockQueue) promoteLocked() {
+	now := q.clock.Now()
+	for {
+		idx := -1
+		for i, w := range q.waiting {
+			if w.readyAt.After(now) {
+				continue
+			}
+			if idx == -1 || w.readyAt.Before(q.waiting[idx].readyAt) {
+				idx = i
+			}
+		}
+		if idx == -1 {
+			return
+		}
+		item := q.waiting[idx].item
+		q.waiting = append(q.waiting[:idx], q.waiting[idx+1:]...)
+		q.addLocked(item)
+	}
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/internal/controller/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+const (
+	// DefaultSignatureHeader is the header HTTP sources read the signature of
+	// payloads from by default, as sent by GitHub.
+	DefaultSignatureHeader = "X-Hub-Signature-256"
+
+	// defaultMaxPayloadBytes is the default maximum size of the payloads of HTTP sources.
+	defaultMaxPayloadBytes = 1 << 20
+)
+
+// HTTPServer is a server HTTP sources can register their path on, such as the
+// webhook server of the manager.
+type HTTPServer interface {
+	Register(path string, hook http.Handler)
+}
+
+// HTTPMapFunc maps the JSON payload received by an HTTP source to the requests to reconcile.
+type HTTPMapFunc func(payload json.RawMessage) ([]reconcile.Request, error)
+
+var _ Source = &HTTP{}
+var _ inject.Stoppable = &HTTP{}
+var _ http.Handler = &HTTP{}
+
+// HTTP is used to provide a source of events posted by systems outside the cluster
+// (e.g. GitHub Webhook callback), without writing an HTTP server pushing events to
+// a Channel.
+//
+// HTTP serves POST requests on Path, either on a webhook server (usually the one of
+// the manager, mgr.GetWebhookServer()) or on its own listener bound to BindAddress.
+// If neither is set, HTTP can be mounted on any server as an http.Handler.
+//
+// The JSON payload of each request is mapped to reconcile.Requests by ToRequests,
+// and a GenericEvent is passed to the handlers for each of them, whose Meta and Object
+// only have the namespace and name of the request set.  Use it with
+// handler.EnqueueRequestForObject.
+type HTTP struct {
+	// Path is the path events are posted on, e.g. /github.
+	Path string
+
+	// Server is the server to register Path on, e.g. mgr.GetWebhookServer().
+	Server HTTPServer
+
+	// BindAddress is the address of the listener serving Path if Server is not set,
+	// e.g. :8090.
+	BindAddress string
+
+	// ToRequests maps the payload of each request to the requests to reconcile.
+	ToRequests HTTPMapFunc
+
+	// Secret is the key of the HMAC-SHA256 signature the payloads must carry in
+	// SignatureHeader, in the form sha256=<hex encoded signature>.  It must be set
+	// unless InsecureSkipVerify is set.
+	Secret []byte
+
+	// InsecureSkipVerify accepts payloads without checking their signature, which
+	// lets anyone able to reach Path trigger reconciles.  Secret must not be set.
+	InsecureSkipVerify bool
+
+	// SignatureHeader is the header carrying the signature of payloads.
+	// Defaults to DefaultSignatureHeader.
+	SignatureHeader string
+
+	// MaxPayloadBytes is the maximum size of payloads.
+	// Defaults to 1MiB.
+	MaxPayloadBytes int64
+
+	// once ensures Path is served only once
+	once sync.Once
+
+	// stop shuts down the listener of the source
+	stop <-chan struct{}
+
+	// destLock guards dest
+	destLock sync.RWMutex
+
+	// dest are the handlers events are delivered to
+	dest []httpDest
+}
+
+// httpDest is an event handler, along with its queue and predicates.
+type httpDest struct {
+	handler    handler.EventHandler
+	queue      workqueue.RateLimitingInterface
+	predicates []predicate.Predicate
+}
+
+func (hs *HTTP) String() string {
+	return fmt.Sprintf("http source: %s", hs.Path)
+}
+
+// InjectStopChannel is internal should be called only by the Controller.
+// It is used to inject the stop channel initialized by the ControllerManager.
+func (hs *HTTP) InjectStopChannel(stop <-chan struct{}) error {
+	if hs.stop == nil {
+		hs.stop = stop
+	}
+	return nil
+}
+
+// Start implements Source and should only be called by the Controller.
+func (hs *HTTP) Start(h handler.EventHandler, queue workqueue.RateLimitingInterface,
+	prct ...predicate.Predicate) error {
+	// Path and ToRequests should have been specified by the user.
+	if !strings.HasPrefix(hs.Path, "/") {
+		return fmt.Errorf("must specify an HTTP.Path starting with \"/\"")
+	}
+	if hs.ToRequests == nil {
+		return fmt.Errorf("must specify HTTP.ToRequests")
+	}
+	if len(hs.Secret) == 0 && !hs.InsecureSkipVerify {
+		return fmt.Errorf("must specify HTTP.Secret, or set HTTP.InsecureSkipVerify to accept unsigned payloads")
+	}
+	if len(hs.Secret) > 0 && hs.InsecureSkipVerify {
+		return fmt.Errorf("must not specify HTTP.Secret with HTTP.InsecureSkipVerify")
+	}
+	if hs.Server != nil && hs.BindAddress != "" {
+		return fmt.Errorf("must specify at most one of HTTP.Server and HTTP.BindAddress")
+	}
+
+	// stop should have been injected before Start was called
+	if hs.BindAddress != "" && hs.stop == nil {
+		return fmt.Errorf("must call InjectStop on HTTP before calling Start")
+	}
+
+	hs.destLock.Lock()
+	hs.dest = append(hs.dest, httpDest{handler: h, queue: queue, predicates: prct})
+	hs.destLock.Unlock()
+
+	var err error
+	hs.once.Do(func() {
+		err = hs.serve()
+	})
+	return err
+}
+
+// serve registers Path on Server, or starts a listener serving it.
+func (hs *HTTP) serve() error {
+	if hs.Server != nil {
+		hs.Server.Register(hs.Path, hs)
+		return nil
+	}
+	if hs.BindAddress == "" {
+		// mounted by the user
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(hs.Path, hs)
+	srv := &http.Server{Addr: hs.BindAddress, Handler: mux}
+	go func() {
+		log.Info("starting http source", "address", hs.BindAddress, "path", hs.Path)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error(err, "http source stopped serving", "address", hs.BindAddress, "path", hs.Path)
+		}
+	}()
+	go func() {
+		<-hs.stop
+		if err := srv.Shutdown(context.Background()); err != nil {
+			log.Error(err, "error shutting down http source", "address", hs.BindAddress, "path", hs.Path)
+		}
+	}()
+	return nil
+}
+
+// ServeHTTP implements http.Handler, passing a GenericEvent to the handlers of the source
+// for each of the requests mapped from the payload.
+func (hs *HTTP) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	code, err := hs.serveHTTP(r)
+	ctrlmetrics.HTTPSourceRequests.WithLabelValues(hs.Path, strconv.Itoa(code)).Inc()
+	if err != nil {
+		log.Error(err, "unable to handle request", "path", hs.Path)
+		http.Error(w, err.Error(), code)
+		return
+	}
+	w.WriteHeader(code)
+}
+
+// serveHTTP handles a request, and returns the status code of the response.
+func (hs *HTTP) serveHTTP(r *http.Request) (int, error) {
+	if r.Method != http.MethodPost {
+		return http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method)
+	}
+
+	maxBytes := hs.MaxPayloadBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxPayloadBytes
+	}
+	payload, err := ioutil.ReadAll(http.MaxBytesReader(nil, r.Body, maxBytes))
+	if err != nil {
+		return http.StatusRequestEntityTooLarge, fmt.Errorf("unable to read the payload: %w", err)
+	}
+
+	if !hs.InsecureSkipVerify {
+		if err := hs.verifySignature(r.Header, payload); err != nil {
+			return http.StatusUnauthorized, err
+		}
+	}
+
+	if !json.Valid(payload) {
+		return http.StatusBadRequest, fmt.Errorf("payload is not valid JSON")
+	}
+	reqs, err := hs.ToRequests(payload)
+	if err != nil {
+		return http.StatusBadRequest, fmt.Errorf("unable to map the payload to requests: %w", err)
+	}
+
+	hs.destLock.RLock()
+	defer hs.destLock.RUnlock()
+	if len(hs.dest) == 0 {
+		return http.StatusServiceUnavailable, fmt.Errorf("http source has not been started")
+	}
+	for _, req := range reqs {
+		obj := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Namespace: req.Namespace, Name: req.Name}}
+		evt := event.GenericEvent{Meta: obj, Object: obj}
+		for _, d := range hs.dest {
+			hs.deliver(d, evt)
+		}
+	}
+	return http.StatusAccepted, nil
+}
+
+// deliver passes evt to the handler of d, if its predicates accept it.
+func (hs *HTTP) deliver(d httpDest, evt event.GenericEvent) {
+	for _, p := range d.predicates {
+		if !p.Generic(evt) {
+			return
+		}
+	}
+	d.handler.Generic(evt, d.queue)
+}
+
+// verifySignature checks that the signature header carries the HMAC-SHA256 of payload.
+func (hs *HTTP) verifySignature(header http.Header, payload []byte) error {
+	name := hs.SignatureHeader
+	if name == "" {
+		name = DefaultSignatureHeader
+	}
+	if len(hs.Secret) == 0 {
+		return fmt.Errorf("no secret to verify the %s header with", name)
+	}
+	value := header.Get(name)
+	if !strings.HasPrefix(value, "sha256=") {
+		return fmt.Errorf("missing or malformed %s header", name)
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(value, "sha256="))
+	if err != nil {
+		return fmt.Errorf("malformed %s header: %w", name, err)
+	}
+
+	mac := hmac.New(sha256.New, hs.Secret)
+	mac.Write(payload) // nolint:errcheck
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return fmt.Errorf("invalid signature in %s header", name)
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/internal/controller/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+var _ source.HTTPServer = &webhook.Server{}
+
+var _ = Describe("HTTP", func() {
+	var q workqueue.RateLimitingInterface
+	var stop chan struct{}
+
+	// toRequests maps payloads like {"repository": {"owner": "foo", "name": "bar"}}
+	// to a request for the repository.
+	toRequests := source.HTTPMapFunc(func(payload json.RawMessage) ([]reconcile.Request, error) {
+		var push struct {
+			Repository struct {
+				Owner string `json:"owner"`
+				Name  string `json:"name"`
+			} `json:"repository"`
+		}
+		if err := json.Unmarshal(payload, &push); err != nil {
+			return nil, err
+		}
+		if push.Repository.Name == "" {
+			return nil, fmt.Errorf("missing repository name")
+		}
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{
+			Namespace: push.Repository.Owner, Name: push.Repository.Name,
+		}}}, nil
+	})
+
+	post := func(h http.Handler, payload string, header http.Header) int {
+		req := httptest.NewRequest(http.MethodPost, "/github", bytes.NewBufferString(payload))
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	sign := func(secret, payload string) http.Header {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(payload)) // nolint:errcheck
+		return http.Header{source.DefaultSignatureHeader: {"sha256=" + hex.EncodeToString(mac.Sum(nil))}}
+	}
+
+	payload := `{"repository": {"owner": "foo", "name": "bar"}}`
+	expected := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "bar"}}
+
+	BeforeEach(func() {
+		q = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test")
+		stop = make(chan struct{})
+	})
+
+	AfterEach(func() {
+		close(stop)
+		q.ShutDown()
+	})
+
+	It("should enqueue the requests mapped from the payload", func() {
+		instance := &source.HTTP{Path: "/github", ToRequests: toRequests, InsecureSkipVerify: true}
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q)).To(Succeed())
+
+		accepted := ctrlmetrics.HTTPSourceRequests.WithLabelValues("/github", "202")
+		acceptedBefore := testutil.ToFloat64(accepted)
+		Expect(post(instance, payload, nil)).To(Equal(http.StatusAccepted))
+		Expect(q.Len()).To(Equal(1))
+		item, _ := q.Get()
+		Expect(item).To(Equal(expected))
+		Expect(testutil.ToFloat64(accepted)).To(Equal(acceptedBefore + 1))
+	})
+
+	It("should apply the predicates to the events", func() {
+		instance := &source.HTTP{Path: "/github", ToRequests: toRequests, InsecureSkipVerify: true}
+		prct := predicate.Funcs{GenericFunc: func(e event.GenericEvent) bool {
+			return e.Meta.GetNamespace() != "foo"
+		}}
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q, prct)).To(Succeed())
+
+		Expect(post(instance, payload, nil)).To(Equal(http.StatusAccepted))
+		Expect(q.Len()).To(Equal(0))
+	})
+
+	It("should reject invalid payloads and methods", func() {
+		instance := &source.HTTP{Path: "/github", ToRequests: toRequests, InsecureSkipVerify: true, MaxPayloadBytes: 64}
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q)).To(Succeed())
+
+		Expect(post(instance, `{"repository":`, nil)).To(Equal(http.StatusBadRequest))
+		Expect(post(instance, `{"repository": {}}`, nil)).To(Equal(http.StatusBadRequest))
+		Expect(post(instance, fmt.Sprintf(`{"repository": {"name": "%0100d"}}`, 0), nil)).To(Equal(http.StatusRequestEntityTooLarge))
+
+		w := httptest.NewRecorder()
+		instance.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/github", nil))
+		Expect(w.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(q.Len()).To(Equal(0))
+	})
+
+	It("should only accept payloads signed with its secret", func() {
+		instance := &source.HTTP{Path: "/github", ToRequests: toRequests, Secret: []byte("s3cr3t")}
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q)).To(Succeed())
+
+		Expect(post(instance, payload, nil)).To(Equal(http.StatusUnauthorized))
+		Expect(post(instance, payload, sign("other", payload))).To(Equal(http.StatusUnauthorized))
+		Expect(post(instance, payload, http.Header{source.DefaultSignatureHeader: {"sha256=zz"}})).To(Equal(http.StatusUnauthorized))
+		Expect(q.Len()).To(Equal(0))
+
+		Expect(post(instance, payload, sign("s3cr3t", payload))).To(Equal(http.StatusAccepted))
+		Expect(q.Len()).To(Equal(1))
+	})
+
+	It("should read the signature from the configured header", func() {
+		instance := &source.HTTP{Path: "/github", ToRequests: toRequests, Secret: []byte("s3cr3t"), SignatureHeader: "X-Signature"}
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q)).To(Succeed())
+
+		header := http.Header{"X-Signature": sign("s3cr3t", payload)[source.DefaultSignatureHeader]}
+		Expect(post(instance, payload, header)).To(Equal(http.StatusAccepted))
+		Expect(q.Len()).To(Equal(1))
+	})
+
+	It("should reject requests before being started", func() {
+		instance := &source.HTTP{Path: "/github", ToRequests: toRequests, InsecureSkipVerify: true}
+		Expect(post(instance, payload, nil)).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	It("should register its path on the webhook server", func() {
+		srv := &webhook.Server{}
+		instance := &source.HTTP{Path: "/github", ToRequests: toRequests, InsecureSkipVerify: true, Server: srv}
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q)).To(Succeed())
+
+		Expect(post(srv.WebhookMux, payload, nil)).To(Equal(http.StatusAccepted))
+		item, _ := q.Get()
+		Expect(item).To(Equal(expected))
+	})
+
+	It("should serve its path on its own listener until stopped", func() {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		addr := l.Addr().String()
+		Expect(l.Close()).To(Succeed())
+
+		instance := &source.HTTP{Path: "/github", ToRequests: toRequests, InsecureSkipVerify: true, BindAddress: addr}
+		Expect(inject.StopChannelInto(stop, instance)).To(BeTrue())
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q)).To(Succeed())
+
+		url := "http://" + addr + "/github"
+		Eventually(func() (int, error) {
+			resp, err := http.Post(url, "application/json", bytes.NewBufferString(payload))
+			if err != nil {
+				return 0, err
+			}
+			return resp.StatusCode, resp.Body.Close()
+		}).Should(Equal(http.StatusAccepted))
+		item, _ := q.Get()
+		Expect(item).To(Equal(expected))
+
+		close(stop)
+		stop = make(chan struct{})
+		Eventually(func() error {
+			resp, err := http.Post(url, "application/json", bytes.NewBufferString(payload))
+			if err == nil {
+				resp.Body.Close()
+			}
+			return err
+		}).Should(HaveOccurred())
+	})
+
+	It("should fail to start without a valid path, a map function or with two servers", func() {
+		instance := &source.HTTP{Path: "github", ToRequests: toRequests, InsecureSkipVerify: true}
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q)).NotTo(Succeed())
+
+		instance = &source.HTTP{Path: "/github", InsecureSkipVerify: true}
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q)).NotTo(Succeed())
+
+		instance = &source.HTTP{Path: "/github", ToRequests: toRequests, InsecureSkipVerify: true, Server: &webhook.Server{}, BindAddress: ":8090"}
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q)).NotTo(Succeed())
+	})
+
+	It("should fail to start without a secret, unless told to skip verification", func() {
+		instance := &source.HTTP{Path: "/github", ToRequests: toRequests}
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q)).NotTo(Succeed())
+
+		instance = &source.HTTP{Path: "/github", ToRequests: toRequests, Secret: []byte("s3cr3t"), InsecureSkipVerify: true}
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q)).NotTo(Succeed())
+
+		By("checking that payloads signed with an empty key are rejected")
+		instance = &source.HTTP{Path: "/github", ToRequests: toRequests}
+		Expect(post(instance, payload, sign("", payload))).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should fail to start its own listener without a stop channel", func() {
+		instance := &source.HTTP{Path: "/github", ToRequests: toRequests, InsecureSkipVerify: true, BindAddress: ":8090"}
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q)).NotTo(Succeed())
+	})
+})
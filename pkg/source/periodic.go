@@ -0,0 +1,180 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/internal/objectutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+var _ Source = &Periodic{}
+var _ inject.Cache = &Periodic{}
+var _ inject.Scheme = &Periodic{}
+var _ inject.Stoppable = &Periodic{}
+
+// Periodic is used to provide a source of GenericEvents for all the objects of a kind in the
+// cache, every Interval.  Unlike the SyncPeriod of the cache, which resyncs the informers of all
+// controllers, it allows each controller to reconcile its objects at its own pace, e.g. to
+// correct drift from external systems.
+//
+// Use it with handler.EnqueueRequestForObject to reconcile each object, or with
+// handler.EnqueueRequestsFromMapFunc to reconcile a set of requests mapped from the objects.
+type Periodic struct {
+	// Type is the type of object to list from the cache.  e.g. &v1.Pod{}
+	Type runtime.Object
+
+	// Interval is the time between two deliveries of events, the first one
+	// happening one Interval after Start.
+	Interval time.Duration
+
+	// JitterFactor, if positive, adds a random duration of up to JitterFactor * Interval
+	// to each Interval, so that controllers started together don't list objects
+	// at the same time.
+	JitterFactor float64
+
+	// ListOptions restrict the objects listed, e.g. to a namespace.
+	ListOptions []client.ListOption
+
+	// cache used to list objects
+	cache cache.Cache
+
+	// scheme used to create lists of objects
+	scheme *runtime.Scheme
+
+	// stop ends the goroutine delivering events
+	stop <-chan struct{}
+}
+
+// Start implements Source and should only be called by the Controller.
+func (ps *Periodic) Start(h handler.EventHandler, queue workqueue.RateLimitingInterface,
+	prct ...predicate.Predicate) error {
+	// Type should have been specified by the user.
+	if ps.Type == nil {
+		return fmt.Errorf("must specify Periodic.Type")
+	}
+	if ps.Interval <= 0 {
+		return fmt.Errorf("must specify a positive Periodic.Interval")
+	}
+
+	// cache, scheme and stop should have been injected before Start was called
+	if ps.cache == nil || ps.scheme == nil {
+		return fmt.Errorf("must call InjectCache and InjectScheme on Periodic before calling Start")
+	}
+	if ps.stop == nil {
+		return fmt.Errorf("must call InjectStop on Periodic before calling Start")
+	}
+
+	list, err := objectutil.NewListFor(ps.Type, ps.scheme)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			timer := time.NewTimer(ps.interval())
+			select {
+			case <-ps.stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			ps.deliver(list.DeepCopyObject(), h, queue, prct)
+		}
+	}()
+	return nil
+}
+
+// interval returns the time until the next delivery of events.
+func (ps *Periodic) interval() time.Duration {
+	if ps.JitterFactor > 0 {
+		return wait.Jitter(ps.Interval, ps.JitterFactor)
+	}
+	return ps.Interval
+}
+
+// deliver lists the objects in the cache and passes a GenericEvent for each of them to the handler.
+func (ps *Periodic) deliver(list runtime.Object, h handler.EventHandler, queue workqueue.RateLimitingInterface,
+	prct []predicate.Predicate) {
+	if err := ps.cache.List(context.TODO(), list, ps.ListOptions...); err != nil {
+		log.Error(err, "unable to list objects for periodic source", "source", ps)
+		return
+	}
+	err := meta.EachListItem(list, func(obj runtime.Object) error {
+		m, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		evt := event.GenericEvent{Meta: m, Object: obj}
+		for _, p := range prct {
+			if !p.Generic(evt) {
+				return nil
+			}
+		}
+		h.Generic(evt, queue)
+		return nil
+	})
+	if err != nil {
+		log.Error(err, "unable to deliver events for periodic source", "source", ps)
+	}
+}
+
+func (ps *Periodic) String() string {
+	if ps.Type != nil && ps.Type.GetObjectKind() != nil {
+		return fmt.Sprintf("periodic source: %v every %v", ps.Type.GetObjectKind().GroupVersionKind().String(), ps.Interval)
+	}
+	return fmt.Sprintf("periodic source: unknown GVK every %v", ps.Interval)
+}
+
+// InjectCache is internal should be called only by the Controller.  InjectCache is used to inject
+// the Cache dependency initialized by the ControllerManager.
+func (ps *Periodic) InjectCache(c cache.Cache) error {
+	if ps.cache == nil {
+		ps.cache = c
+	}
+	return nil
+}
+
+// InjectScheme is internal should be called only by the Controller.  InjectScheme is used to inject
+// the Scheme dependency initialized by the ControllerManager.
+func (ps *Periodic) InjectScheme(s *runtime.Scheme) error {
+	if ps.scheme == nil {
+		ps.scheme = s
+	}
+	return nil
+}
+
+// InjectStopChannel is internal should be called only by the Controller.
+// It is used to inject the stop channel initialized by the ControllerManager.
+func (ps *Periodic) InjectStopChannel(stop <-chan struct{}) error {
+	if ps.stop == nil {
+		ps.stop = stop
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var _ = Describe("Periodic", func() {
+	var c cache.Cache
+	var q workqueue.RateLimitingInterface
+	var stop chan struct{}
+
+	BeforeEach(func() {
+		cl := fake.NewFakeClientWithScheme(scheme.Scheme,
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "pod-1"}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "pod-2"}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "bar", Name: "pod-3"}},
+		)
+		var err error
+		c, err = informertest.NewFakeCache(scheme.Scheme, cl)
+		Expect(err).NotTo(HaveOccurred())
+		q = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test")
+		stop = make(chan struct{})
+
+		go func() {
+			defer GinkgoRecover()
+			Expect(c.Start(stop)).To(Succeed())
+		}()
+		Expect(c.WaitForCacheSync(stop)).To(BeTrue())
+	})
+
+	AfterEach(func() {
+		close(stop)
+		q.ShutDown()
+	})
+
+	injectInto := func(instance *source.Periodic) {
+		Expect(inject.CacheInto(c, instance)).To(BeTrue())
+		Expect(inject.SchemeInto(scheme.Scheme, instance)).To(BeTrue())
+		Expect(inject.StopChannelInto(stop, instance)).To(BeTrue())
+	}
+
+	It("should enqueue all the objects of its type every interval", func() {
+		instance := &source.Periodic{Type: &corev1.Pod{}, Interval: 20 * time.Millisecond, JitterFactor: 0.5}
+		injectInto(instance)
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q)).To(Succeed())
+
+		Eventually(q.Len).Should(Equal(3))
+		for i := 0; i < 3; i++ {
+			item, _ := q.Get()
+			q.Done(item)
+		}
+		Eventually(q.Len).Should(Equal(3))
+	})
+
+	It("should only list the objects matching its ListOptions and predicates", func() {
+		instance := &source.Periodic{
+			Type:        &corev1.Pod{},
+			Interval:    10 * time.Millisecond,
+			ListOptions: []client.ListOption{client.InNamespace("foo")},
+		}
+		injectInto(instance)
+		prct := predicate.Funcs{GenericFunc: func(e event.GenericEvent) bool {
+			return e.Meta.GetName() != "pod-1"
+		}}
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q, prct)).To(Succeed())
+
+		Eventually(q.Len).Should(Equal(1))
+		Consistently(q.Len, 50*time.Millisecond).Should(Equal(1))
+		item, _ := q.Get()
+		Expect(item).To(Equal(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "pod-2"}}))
+	})
+
+	It("should enqueue the requests mapped from the objects", func() {
+		instance := &source.Periodic{Type: &corev1.Pod{}, Interval: 10 * time.Millisecond}
+		injectInto(instance)
+		h := &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(func(o handler.MapObject) []reconcile.Request {
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: o.Meta.GetNamespace()}}}
+		})}
+		Expect(instance.Start(h, q)).To(Succeed())
+
+		Eventually(q.Len).Should(Equal(2))
+	})
+
+	It("should stop delivering events when stopped", func() {
+		instance := &source.Periodic{Type: &corev1.Pod{}, Interval: 10 * time.Millisecond}
+		injectInto(instance)
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q)).To(Succeed())
+		Eventually(q.Len).Should(Equal(3))
+
+		close(stop)
+		stop = make(chan struct{})
+		// let a delivery in progress finish
+		time.Sleep(20 * time.Millisecond)
+		for q.Len() > 0 {
+			item, _ := q.Get()
+			q.Done(item)
+		}
+		Consistently(q.Len, 50*time.Millisecond).Should(Equal(0))
+	})
+
+	It("should fail to start without a type or a positive interval", func() {
+		instance := &source.Periodic{Interval: time.Second}
+		injectInto(instance)
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q)).NotTo(Succeed())
+
+		instance = &source.Periodic{Type: &corev1.Pod{}}
+		injectInto(instance)
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q)).NotTo(Succeed())
+	})
+
+	It("should fail to start if its dependencies have not been injected", func() {
+		instance := &source.Periodic{Type: &corev1.Pod{}, Interval: time.Second}
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q)).NotTo(Succeed())
+	})
+})
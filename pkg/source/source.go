@@ -27,6 +27,7 @@ import (
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/internal/controller/metrics"
 	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
 	"sigs.k8s.io/controller-runtime/pkg/source/internal"
@@ -49,6 +50,8 @@ const (
 //
 // * Use Channel for events originating outside the cluster (eh.g. GitHub Webhook callback, Polling external urls).
 //
+// * Use HTTP for events posted to the controller by systems outside the cluster (e.g. GitHub Webhook callback).
+//
 // Users may build their own Source implementations.  If their implementations implement any of the inject package
 // interfaces, the dependencies will be injected by the Controller when Watch is called.
 type Source interface {
@@ -153,9 +156,30 @@ func (ks *Kind) InjectCache(c cache.Cache) error {
 
 var _ Source = &Channel{}
 
+// ChannelBufferPolicy decides what a Channel does with an event when the buffer
+// of one of its destinations is full.
+type ChannelBufferPolicy string
+
+const (
+	// BlockWhenFull waits for the destination to make room for the event.  This
+	// delays the delivery of the event, and of the following events, to all the
+	// other destinations of the Channel.
+	BlockWhenFull ChannelBufferPolicy = "Block"
+
+	// DropOldestWhenFull drops the oldest event in the buffer of the destination to
+	// make room for the event.
+	DropOldestWhenFull ChannelBufferPolicy = "DropOldest"
+
+	// DropNewestWhenFull drops the event for the destination.
+	DropNewestWhenFull ChannelBufferPolicy = "DropNewest"
+)
+
 // Channel is used to provide a source of events originating outside the cluster
 // (e.g. GitHub Webhook callback).  Channel requires the user to wire the external
 // source (eh.g. http handler) to write GenericEvents to the underlying channel.
+//
+// Closing the Source channel, or stopping the manager, ends the delivery of events
+// and releases the goroutines of the Channel.
 type Channel struct {
 	// once ensures the event distribution goroutine will be performed only once
 	once sync.Once
@@ -173,8 +197,21 @@ type Channel struct {
 	// Default to 1024 if not specified.
 	DestBufferSize int
 
+	// FullBufferPolicy decides what to do with an event when the buffer of a dest
+	// channel is full.  Dropped events are counted by the
+	// controller_runtime_channel_source_events_dropped_total metric.
+	// Defaults to BlockWhenFull.
+	FullBufferPolicy ChannelBufferPolicy
+
+	// Name identifies the Channel in the metrics.
+	// Defaults to "channel".
+	Name string
+
 	// destLock is to ensure the destination channels are safely added/removed
 	destLock sync.Mutex
+
+	// stopped is set once the dest channels have been closed
+	stopped bool
 }
 
 func (cs *Channel) String() string {
@@ -209,10 +246,24 @@ func (cs *Channel) Start(
 	}
 
 	// use default value if DestBufferSize not specified
+	if cs.DestBufferSize < 0 {
+		return fmt.Errorf("must specify a non-negative Channel.DestBufferSize")
+	}
 	if cs.DestBufferSize == 0 {
 		cs.DestBufferSize = defaultBufferSize
 	}
 
+	switch cs.FullBufferPolicy {
+	case "":
+		cs.FullBufferPolicy = BlockWhenFull
+	case BlockWhenFull, DropOldestWhenFull, DropNewestWhenFull:
+	default:
+		return fmt.Errorf("unknown Channel.FullBufferPolicy %q", cs.FullBufferPolicy)
+	}
+	if cs.Name == "" {
+		cs.Name = "channel"
+	}
+
 	cs.once.Do(func() {
 		// Distribute GenericEvents to all EventHandler / Queue pairs Watching this source
 		go cs.syncLoop()
@@ -238,6 +289,11 @@ func (cs *Channel) Start(
 	cs.destLock.Lock()
 	defer cs.destLock.Unlock()
 
+	if cs.stopped {
+		// nothing would be written to dst, let its goroutine end
+		close(dst)
+		return fmt.Errorf("channel source has been stopped or its Source closed")
+	}
 	cs.dest = append(cs.dest, dst)
 
 	return nil
@@ -250,6 +306,8 @@ func (cs *Channel) doStop() {
 	for _, dst := range cs.dest {
 		close(dst)
 	}
+	cs.dest = nil
+	cs.stopped = true
 }
 
 func (cs *Channel) distribute(evt event.GenericEvent) {
@@ -260,9 +318,42 @@ func (cs *Channel) distribute(evt event.GenericEvent) {
 		// We cannot make it under goroutine here, or we'll meet the
 		// race condition of writing message to closed channels.
 		// To avoid blocking, the dest channels are expected to be of
-		// proper buffer size. If we still see it blocked, then
-		// the controller is thought to be in an abnormal state.
-		dst <- evt
+		// proper buffer size, or to drop events when full.
+		switch cs.FullBufferPolicy {
+		case DropNewestWhenFull:
+			select {
+			case dst <- evt:
+			default:
+				ctrlmetrics.ChannelSourceEventsDropped.WithLabelValues(cs.Name).Inc()
+			}
+		case DropOldestWhenFull:
+			cs.sendDroppingOldest(dst, evt)
+		default:
+			select {
+			case dst <- evt:
+			case <-cs.stop:
+				// don't stay blocked on a stalled handler once stopped
+				return
+			}
+		}
+	}
+}
+
+// sendDroppingOldest writes evt to dst, dropping the oldest events of dst until
+// there is room for it.  It is only called with destLock held, so no other event
+// may be written to dst in the meantime.
+func (cs *Channel) sendDroppingOldest(dst chan event.GenericEvent, evt event.GenericEvent) {
+	for {
+		select {
+		case dst <- evt:
+			return
+		default:
+		}
+		select {
+		case <-dst:
+			ctrlmetrics.ChannelSourceEventsDropped.WithLabelValues(cs.Name).Inc()
+		default:
+		}
 	}
 }
 
@@ -273,7 +364,12 @@ func (cs *Channel) syncLoop() {
 			// Close destination channels
 			cs.doStop()
 			return
-		case evt := <-cs.Source:
+		case evt, ok := <-cs.Source:
+			if !ok {
+				// The Source has been closed, no more events will come
+				cs.doStop()
+				return
+			}
 			cs.distribute(evt)
 		}
 	}
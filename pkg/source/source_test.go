@@ -18,12 +18,15 @@ package source_test
 
 import (
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/internal/controller/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
 	"sigs.k8s.io/controller-runtime/pkg/source"
@@ -428,6 +431,125 @@ var _ = Describe("Source", func() {
 			})
 
 		})
+		Context("with a full buffer", func() {
+			podEvent := func(name string) event.GenericEvent {
+				p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "bar"}}
+				return event.GenericEvent{Object: p, Meta: p}
+			}
+
+			// startBlocked starts instance with a handler blocking on the first event until
+			// unblock is closed, and returns the names of the handled events.
+			startBlocked := func(instance *source.Channel, ch chan event.GenericEvent, unblock chan struct{}) chan string {
+				handled := make(chan string, 10)
+				blocked := make(chan struct{})
+				q := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test")
+				Expect(inject.StopChannelInto(stop, instance)).To(BeTrue())
+				err := instance.Start(handler.Funcs{
+					GenericFunc: func(evt event.GenericEvent, _ workqueue.RateLimitingInterface) {
+						if evt.Meta.GetName() == "first" {
+							close(blocked)
+							<-unblock
+						}
+						handled <- evt.Meta.GetName()
+					},
+				}, q)
+				Expect(err).NotTo(HaveOccurred())
+
+				ch <- podEvent("first")
+				<-blocked
+				return handled
+			}
+
+			It("should drop the newest events with DropNewestWhenFull", func(done Done) {
+				ch := make(chan event.GenericEvent)
+				unblock := make(chan struct{})
+				instance := &source.Channel{Source: ch, DestBufferSize: 1, FullBufferPolicy: source.DropNewestWhenFull, Name: "drop-newest"}
+				handled := startBlocked(instance, ch, unblock)
+
+				dropped := ctrlmetrics.ChannelSourceEventsDropped.WithLabelValues("drop-newest")
+				droppedBefore := testutil.ToFloat64(dropped)
+				ch <- podEvent("second")
+				ch <- podEvent("third")
+				Eventually(func() float64 { return testutil.ToFloat64(dropped) }).Should(Equal(droppedBefore + 1))
+
+				close(unblock)
+				Expect(<-handled).To(Equal("first"))
+				Expect(<-handled).To(Equal("second"))
+				Consistently(handled, 50*time.Millisecond).ShouldNot(Receive())
+				close(done)
+			})
+
+			It("should drop the oldest events with DropOldestWhenFull", func(done Done) {
+				ch := make(chan event.GenericEvent)
+				unblock := make(chan struct{})
+				instance := &source.Channel{Source: ch, DestBufferSize: 1, FullBufferPolicy: source.DropOldestWhenFull, Name: "drop-oldest"}
+				handled := startBlocked(instance, ch, unblock)
+
+				dropped := ctrlmetrics.ChannelSourceEventsDropped.WithLabelValues("drop-oldest")
+				droppedBefore := testutil.ToFloat64(dropped)
+				ch <- podEvent("second")
+				ch <- podEvent("third")
+				Eventually(func() float64 { return testutil.ToFloat64(dropped) }).Should(Equal(droppedBefore + 1))
+
+				close(unblock)
+				Expect(<-handled).To(Equal("first"))
+				Expect(<-handled).To(Equal("third"))
+				Consistently(handled, 50*time.Millisecond).ShouldNot(Receive())
+				close(done)
+			})
+
+			It("should stop waiting for a blocked handler when stopped", func(done Done) {
+				ch := make(chan event.GenericEvent)
+				unblock := make(chan struct{})
+				instance := &source.Channel{Source: ch, DestBufferSize: 1}
+				localStop := make(chan struct{})
+				Expect(inject.StopChannelInto(localStop, instance)).To(BeTrue())
+				handled := startBlocked(instance, ch, unblock)
+
+				ch <- podEvent("second")
+				ch <- podEvent("third")
+				close(localStop)
+
+				By("checking that the channel source doesn't accept events anymore")
+				Consistently(ch, 50*time.Millisecond).ShouldNot(BeSent(podEvent("fourth")))
+
+				close(unblock)
+				Expect(<-handled).To(Equal("first"))
+				close(done)
+			})
+
+			It("should fail to start with an unknown policy or a negative buffer size", func(done Done) {
+				q := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test")
+				instance := &source.Channel{Source: ch, FullBufferPolicy: "DropAll"}
+				Expect(inject.StopChannelInto(stop, instance)).To(BeTrue())
+				Expect(instance.Start(handler.Funcs{}, q)).NotTo(Succeed())
+
+				instance = &source.Channel{Source: ch, DestBufferSize: -1}
+				Expect(inject.StopChannelInto(stop, instance)).To(BeTrue())
+				Expect(instance.Start(handler.Funcs{}, q)).NotTo(Succeed())
+				close(done)
+			})
+		})
+		Context("once its Source is closed", func() {
+			It("should stop delivering events and refuse new handlers", func(done Done) {
+				ch := make(chan event.GenericEvent)
+				q := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test")
+				instance := &source.Channel{Source: ch}
+				Expect(inject.StopChannelInto(stop, instance)).To(BeTrue())
+				Expect(instance.Start(handler.Funcs{
+					GenericFunc: func(event.GenericEvent, workqueue.RateLimitingInterface) {
+						defer GinkgoRecover()
+						Fail("Unexpected GenericEvent")
+					},
+				}, q)).To(Succeed())
+
+				close(ch)
+				Eventually(func() error {
+					return instance.Start(handler.Funcs{}, q)
+				}).Should(MatchError("channel source has been stopped or its Source closed"))
+				close(done)
+			})
+		})
 		Context("for multi sources (handlers)", func() {
 			It("should provide GenericEvents for all handlers", func(done Done) {
 				ch := make(chan event.GenericEvent)
@@ -0,0 +1,149 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+// TypedKind is a Kind for objects of type T, e.g. TypedKind[*corev1.Pod].  Its events can be
+// handled by a handler.TypedEventHandler and filtered by predicate.TypedPredicates for T, and
+// it can be used wherever a Source is expected, along with untyped handlers and predicates.
+type TypedKind[T runtime.Object] struct {
+	// Type is the type of object to watch.  It defaults to a new, empty object of type T,
+	// which only needs to be set for types such as unstructured objects that don't
+	// carry their kind in their Go type.
+	Type T
+
+	// Handler, if set, handles the events of the source in addition to the handler
+	// passed to Start.
+	Handler handler.TypedEventHandler[T]
+
+	// Predicates filter the events of the source, in addition to the predicates
+	// passed to Start.
+	Predicates []predicate.TypedPredicate[T]
+
+	kind Kind
+}
+
+var _ SyncingSource = &TypedKind[runtime.Object]{}
+var _ inject.Cache = &TypedKind[runtime.Object]{}
+
+// Start implements Source.  The given handler may be nil if Handler is set.
+func (ks *TypedKind[T]) Start(h handler.EventHandler, queue workqueue.RateLimitingInterface,
+	prct ...predicate.Predicate) error {
+	if ks.kind.Type == nil {
+		obj, err := ks.object()
+		if err != nil {
+			return err
+		}
+		ks.kind.Type = obj
+	}
+
+	for _, p := range ks.Predicates {
+		prct = append(prct, predicate.Untyped(p))
+	}
+
+	switch {
+	case ks.Handler != nil && h != nil:
+		h = multiHandler{h, handler.Untyped(ks.Handler)}
+	case ks.Handler != nil:
+		h = handler.Untyped(ks.Handler)
+	case h == nil:
+		return fmt.Errorf("must specify an event handler or TypedKind.Handler")
+	}
+	return ks.kind.Start(h, queue, prct...)
+}
+
+// object returns the object to watch.
+func (ks *TypedKind[T]) object() (runtime.Object, error) {
+	if !reflect.ValueOf(&ks.Type).Elem().IsZero() {
+		return ks.Type, nil
+	}
+	t := reflect.TypeOf(&ks.Type).Elem()
+	if t.Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("must specify TypedKind.Type for %v", t)
+	}
+	obj, ok := reflect.New(t.Elem()).Interface().(runtime.Object)
+	if !ok {
+		return nil, fmt.Errorf("must specify TypedKind.Type for %v", t)
+	}
+	return obj, nil
+}
+
+func (ks *TypedKind[T]) String() string {
+	return ks.kind.String()
+}
+
+// WaitForSync implements SyncingSource.
+func (ks *TypedKind[T]) WaitForSync(stop <-chan struct{}) error {
+	return ks.kind.WaitForSync(stop)
+}
+
+// InjectCache is internal should be called only by the Controller.  InjectCache is used to inject
+// the Cache dependency initialized by the ControllerManager.
+func (ks *TypedKind[T]) InjectCache(c cache.Cache) error {
+	return ks.kind.InjectCache(c)
+}
+
+// InjectFunc implements inject.Injector, injecting fields into Handler.
+func (ks *TypedKind[T]) InjectFunc(f inject.Func) error {
+	if f == nil || ks.Handler == nil {
+		return nil
+	}
+	return f(ks.Handler)
+}
+
+// multiHandler passes events to several EventHandlers.
+type multiHandler []handler.EventHandler
+
+// Create implements EventHandler
+func (m multiHandler) Create(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+	for _, h := range m {
+		h.Create(e, q)
+	}
+}
+
+// Update implements EventHandler
+func (m multiHandler) Update(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	for _, h := range m {
+		h.Update(e, q)
+	}
+}
+
+// Delete implements EventHandler
+func (m multiHandler) Delete(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	for _, h := range m {
+		h.Delete(e, q)
+	}
+}
+
+// Generic implements EventHandler
+func (m multiHandler) Generic(e event.GenericEvent, q workqueue.RateLimitingInterface) {
+	for _, h := range m {
+		h.Generic(e, q)
+	}
+}
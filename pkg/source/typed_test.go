@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var _ = Describe("TypedKind", func() {
+	var ic *informertest.FakeInformers
+	var q workqueue.RateLimitingInterface
+
+	BeforeEach(func() {
+		ic = &informertest.FakeInformers{}
+		q = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test")
+	})
+
+	AfterEach(func() {
+		q.ShutDown()
+	})
+
+	nodeOf := func(pod *corev1.Pod) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: pod.Spec.NodeName}}}
+	}
+
+	It("should watch the type of its parameter and deliver typed events to its handler", func() {
+		instance := &source.TypedKind[*corev1.Pod]{
+			Handler: &handler.TypedEnqueueRequestsFromMapFunc[*corev1.Pod]{ToRequests: nodeOf},
+			Predicates: []predicate.TypedPredicate[*corev1.Pod]{predicate.TypedFuncs[*corev1.Pod]{
+				CreateFunc: func(e event.TypedCreateEvent[*corev1.Pod]) bool {
+					return e.Object.Spec.NodeName != ""
+				},
+			}},
+		}
+		Expect(instance.InjectCache(ic)).To(Succeed())
+		Expect(instance.Start(nil, q)).To(Succeed())
+
+		i, err := ic.FakeInformerFor(&corev1.Pod{})
+		Expect(err).NotTo(HaveOccurred())
+
+		i.Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "unscheduled"}})
+		i.Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "baz"}, Spec: corev1.PodSpec{NodeName: "node-1"}})
+		Expect(q.Len()).To(Equal(1))
+		item, _ := q.Get()
+		Expect(item).To(Equal(reconcile.Request{NamespacedName: types.NamespacedName{Name: "node-1"}}))
+	})
+
+	It("should deliver events to both its handler and the handler passed to Start", func() {
+		instance := &source.TypedKind[*corev1.Pod]{
+			Handler: &handler.TypedEnqueueRequestsFromMapFunc[*corev1.Pod]{ToRequests: nodeOf},
+		}
+		Expect(instance.InjectCache(ic)).To(Succeed())
+		Expect(instance.Start(&handler.EnqueueRequestForObject{}, q)).To(Succeed())
+
+		i, err := ic.FakeInformerFor(&corev1.Pod{})
+		Expect(err).NotTo(HaveOccurred())
+
+		i.Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "baz"}, Spec: corev1.PodSpec{NodeName: "node-1"}})
+		Expect(q.Len()).To(Equal(2))
+	})
+
+	It("should require a handler", func() {
+		instance := &source.TypedKind[*corev1.Pod]{}
+		Expect(instance.InjectCache(ic)).To(Succeed())
+		Expect(instance.Start(nil, q)).NotTo(Succeed())
+	})
+})
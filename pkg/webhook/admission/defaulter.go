@@ -63,6 +63,9 @@ func (h *mutatingHandler) Handle(ctx context.Context, req Request) Response {
 		return Errored(http.StatusBadRequest, err)
 	}
 
+	// Warn about the object as it was received, before defaulting it
+	warnings := warningsFor(obj)
+
 	// Default the object
 	obj.Default()
 	marshalled, err := json.Marshal(obj)
@@ -71,5 +74,5 @@ func (h *mutatingHandler) Handle(ctx context.Context, req Request) Response {
 	}
 
 	// Create the patch
-	return PatchResponseFromRaw(req.Object.Raw, marshalled)
+	return PatchResponseFromRaw(req.Object.Raw, marshalled).WithWarnings(warnings...)
 }
@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+// CustomDefaulter defines functions for setting defaults on objects of a
+// given type.  Unlike Defaulter, it is implemented by a separate type, which
+// can have dependencies (such as a client) injected, and receives the context
+// of the request, from which the admission Request can be retrieved with
+// RequestFromContext.
+type CustomDefaulter interface {
+	Default(ctx context.Context, obj runtime.Object) error
+}
+
+// WithCustomDefaulter creates a new Webhook for defaulting objects of the type
+// of obj with the given CustomDefaulter.
+func WithCustomDefaulter(obj runtime.Object, defaulter CustomDefaulter) *Webhook {
+	return &Webhook{
+		Handler: &defaulterForType{object: obj, defaulter: defaulter},
+	}
+}
+
+type defaulterForType struct {
+	object    runtime.Object
+	defaulter CustomDefaulter
+	decoder   *Decoder
+}
+
+var _ DecoderInjector = &defaulterForType{}
+var _ inject.Injector = &defaulterForType{}
+
+// InjectDecoder injects the decoder into a defaulterForType.
+func (h *defaulterForType) InjectDecoder(d *Decoder) error {
+	h.decoder = d
+	return nil
+}
+
+// InjectFunc injects dependencies into the defaulter.
+func (h *defaulterForType) InjectFunc(f inject.Func) error {
+	return f(h.defaulter)
+}
+
+// Handle handles admission requests.
+func (h *defaulterForType) Handle(ctx context.Context, req Request) Response {
+	if h.defaulter == nil {
+		panic("defaulter should never be nil")
+	}
+	if h.object == nil {
+		panic("object should never be nil")
+	}
+
+	ctx = NewContextWithRequest(ctx, req)
+
+	// Get the object in the request
+	obj := h.object.DeepCopyObject()
+	if err := h.decoder.Decode(req, obj); err != nil {
+		return Errored(http.StatusBadRequest, err)
+	}
+
+	// Warn about the object as it was received, before defaulting it
+	var warnings Warnings
+	if warner, ok := h.defaulter.(CustomWarner); ok {
+		warnings = warner.AdmissionWarnings(ctx, obj)
+	}
+
+	// Default the object
+	if err := h.defaulter.Default(ctx, obj); err != nil {
+		return Denied(err.Error()).WithWarnings(warnings...)
+	}
+	marshalled, err := json.Marshal(obj)
+	if err != nil {
+		return Errored(http.StatusInternalServerError, err)
+	}
+
+	// Create the patch
+	return PatchResponseFromRaw(req.Object.Raw, marshalled).WithWarnings(warnings...)
+}
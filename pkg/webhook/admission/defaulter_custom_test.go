@@ -0,0 +1,69 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"gomodules.xyz/jsonpatch/v2"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+)
+
+var _ = Describe("CustomDefaulter", func() {
+	It("should default objects with the request available from the context", func() {
+		webhook := WithCustomDefaulter(&corev1.Pod{}, &podDefaulter{})
+		webhook.log = logf.RuntimeLog.WithName("webhook")
+		Expect(webhook.InjectScheme(scheme.Scheme)).To(Succeed())
+
+		resp := webhook.Handle(context.Background(), Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object: runtime.RawExtension{
+				Raw: []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"foo","namespace":"default"}}`),
+			},
+			UserInfo: authenticationv1.UserInfo{Username: "alice"},
+		}})
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Patches).To(ContainElement(jsonpatch.JsonPatchOperation{
+			Operation: "add",
+			Path:      "/metadata/annotations",
+			Value:     map[string]interface{}{"created-by": "alice"},
+		}))
+	})
+})
+
+// podDefaulter annotates pods with the name of the user creating them.
+type podDefaulter struct{}
+
+var _ CustomDefaulter = &podDefaulter{}
+
+func (d *podDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	req, err := RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	obj.(*corev1.Pod).Annotations = map[string]string{"created-by": req.UserInfo.Username}
+	return nil
+}
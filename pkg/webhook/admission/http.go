@@ -24,20 +24,50 @@ import (
 	"io/ioutil"
 	"net/http"
 
-	"k8s.io/api/admission/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/internal/timeout"
 )
 
 var admissionScheme = runtime.NewScheme()
 var admissionCodecs = serializer.NewCodecFactory(admissionScheme)
 
 func init() {
+	utilruntime.Must(admissionv1.AddToScheme(admissionScheme))
 	utilruntime.Must(admissionv1beta1.AddToScheme(admissionScheme))
 }
 
+// unversionedAdmissionReview is used to decode both v1 and v1beta1
+// AdmissionReviews, which have the same structure.  Since it is not
+// registered in the scheme, the decoder leaves its type information alone
+// and reports the version that was actually received.
+type unversionedAdmissionReview struct {
+	admissionv1.AdmissionReview
+}
+
+var _ runtime.Object = &unversionedAdmissionReview{}
+
+// admissionReviewResponse is the AdmissionReview written in reply to a
+// request.  It has the same structure as an AdmissionReview, except that
+// the response carries warnings too.
+type admissionReviewResponse struct {
+	metav1.TypeMeta `json:",inline"`
+	Response        *admissionResponse `json:"response,omitempty"`
+}
+
+// admissionResponse is an AdmissionResponse with the warnings field added in
+// Kubernetes 1.19.
+type admissionResponse struct {
+	*admissionv1.AdmissionResponse `json:",inline"`
+	Warnings                       []string `json:"warnings,omitempty"`
+}
+
 var _ http.Handler = &Webhook{}
 
 func (wh *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -71,27 +101,58 @@ func (wh *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	req := Request{}
-	ar := v1beta1.AdmissionReview{
-		// avoid an extra copy
-		Request: &req.AdmissionRequest,
-	}
-	if _, _, err := admissionCodecs.UniversalDeserializer().Decode(body, nil, &ar); err != nil {
+	ar := unversionedAdmissionReview{}
+	// avoid an extra copy
+	ar.Request = &req.AdmissionRequest
+	_, reviewGVK, err := admissionCodecs.UniversalDeserializer().Decode(body, nil, &ar)
+	if err != nil {
 		wh.log.Error(err, "unable to decode the request")
 		reviewResponse = Errored(http.StatusBadRequest, err)
 		wh.writeResponse(w, reviewResponse)
 		return
 	}
+	// requests without type information are accepted for backwards
+	// compatibility, and answered the same way
+	if !reviewGVK.Empty() && !admissionScheme.Recognizes(*reviewGVK) {
+		err = fmt.Errorf("unsupported AdmissionReview version %q", reviewGVK.GroupVersion())
+		wh.log.Error(err, "unable to process a request with an unknown AdmissionReview version", "kind", reviewGVK)
+		reviewResponse = Errored(http.StatusBadRequest, err)
+		wh.writeResponse(w, reviewResponse)
+		return
+	}
 	wh.log.V(1).Info("received request", "UID", req.UID, "kind", req.Kind, "resource", req.Resource)
 
-	// TODO: add panic-recovery for Handle
-	reviewResponse = wh.Handle(r.Context(), req)
-	wh.writeResponse(w, reviewResponse)
+	ctx, cancel, err := timeout.Context(r)
+	if err != nil {
+		wh.log.Error(err, "unable to process a request with an invalid timeout")
+		reviewResponse = Errored(http.StatusBadRequest, err)
+		wh.writeResponseTyped(w, reviewResponse, reviewGVK)
+		return
+	}
+	defer cancel()
+
+	reviewResponse = wh.Handle(ctx, req)
+	wh.writeResponseTyped(w, reviewResponse, reviewGVK)
 }
 
+// writeResponse writes response to w, without any AdmissionReview type
+// information.
 func (wh *Webhook) writeResponse(w io.Writer, response Response) {
+	wh.writeResponseTyped(w, response, nil)
+}
+
+// writeResponseTyped writes response to w as an AdmissionReview of the
+// given version, which should be the version of the request.
+func (wh *Webhook) writeResponseTyped(w io.Writer, response Response, reviewGVK *schema.GroupVersionKind) {
 	encoder := json.NewEncoder(w)
-	responseAdmissionReview := v1beta1.AdmissionReview{
-		Response: &response.AdmissionResponse,
+	responseAdmissionReview := admissionReviewResponse{
+		Response: &admissionResponse{
+			AdmissionResponse: &response.AdmissionResponse,
+			Warnings:          response.Warnings,
+		},
+	}
+	if reviewGVK != nil {
+		responseAdmissionReview.APIVersion, responseAdmissionReview.Kind = reviewGVK.ToAPIVersionAndKind()
 	}
 	err := encoder.Encode(responseAdmissionReview)
 	if err != nil {
@@ -99,6 +160,6 @@ func (wh *Webhook) writeResponse(w io.Writer, response Response) {
 		wh.writeResponse(w, Errored(http.StatusInternalServerError, err))
 	} else {
 		res := responseAdmissionReview.Response
-		wh.log.V(1).Info("wrote response", "UID", res.UID, "allowed", res.Allowed, "result", res.Result)
+		wh.log.V(1).Info("wrote response", "UID", res.UID, "allowed", res.Allowed, "result", res.Result, "warnings", res.Warnings)
 	}
 }
@@ -23,13 +23,14 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
 
-	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
 	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
 )
 
@@ -98,6 +99,120 @@ var _ = Describe("Admission Webhooks", func() {
 			Expect(respRecorder.Body.Bytes()).To(Equal(expected))
 		})
 
+		It("should respond with a v1 AdmissionReview to a v1 AdmissionReview", func() {
+			req := &http.Request{
+				Header: http.Header{"Content-Type": []string{"application/json"}},
+				Body: nopCloser{Reader: bytes.NewBufferString(
+					`{"kind":"AdmissionReview","apiVersion":"admission.k8s.io/v1","request":{"uid":"123"}}`)},
+			}
+			webhook := &Webhook{
+				Handler: &fakeHandler{},
+				log:     logf.RuntimeLog.WithName("webhook"),
+			}
+
+			expected := []byte(`{"kind":"AdmissionReview","apiVersion":"admission.k8s.io/v1","response":{"uid":"123","allowed":true,"status":{"metadata":{},"code":200}}}
+`)
+			webhook.ServeHTTP(respRecorder, req)
+			Expect(respRecorder.Body.Bytes()).To(Equal(expected))
+		})
+
+		It("should respond with a v1beta1 AdmissionReview to a v1beta1 AdmissionReview", func() {
+			req := &http.Request{
+				Header: http.Header{"Content-Type": []string{"application/json"}},
+				Body: nopCloser{Reader: bytes.NewBufferString(
+					`{"kind":"AdmissionReview","apiVersion":"admission.k8s.io/v1beta1","request":{"uid":"123"}}`)},
+			}
+			webhook := &Webhook{
+				Handler: &fakeHandler{},
+				log:     logf.RuntimeLog.WithName("webhook"),
+			}
+
+			expected := []byte(`{"kind":"AdmissionReview","apiVersion":"admission.k8s.io/v1beta1","response":{"uid":"123","allowed":true,"status":{"metadata":{},"code":200}}}
+`)
+			webhook.ServeHTTP(respRecorder, req)
+			Expect(respRecorder.Body.Bytes()).To(Equal(expected))
+		})
+
+		It("should include the warnings of the response", func() {
+			req := &http.Request{
+				Header: http.Header{"Content-Type": []string{"application/json"}},
+				Body: nopCloser{Reader: bytes.NewBufferString(
+					`{"kind":"AdmissionReview","apiVersion":"admission.k8s.io/v1","request":{"uid":"123"}}`)},
+			}
+			webhook := &Webhook{
+				Handler: &fakeHandler{
+					fn: func(ctx context.Context, req Request) Response {
+						return Allowed("").WithWarnings("spec.foo is deprecated", "spec.bar is deprecated")
+					},
+				},
+				log: logf.RuntimeLog.WithName("webhook"),
+			}
+
+			expected := []byte(`{"kind":"AdmissionReview","apiVersion":"admission.k8s.io/v1","response":{"uid":"123","allowed":true,"status":{"metadata":{},"code":200},"warnings":["spec.foo is deprecated","spec.bar is deprecated"]}}
+`)
+			webhook.ServeHTTP(respRecorder, req)
+			Expect(respRecorder.Body.Bytes()).To(Equal(expected))
+		})
+
+		It("should return bad-request when given an unknown AdmissionReview version", func() {
+			req := &http.Request{
+				Header: http.Header{"Content-Type": []string{"application/json"}},
+				Body: nopCloser{Reader: bytes.NewBufferString(
+					`{"kind":"AdmissionReview","apiVersion":"admission.k8s.io/v2","request":{"uid":"123"}}`)},
+			}
+			handler := &fakeHandler{}
+			webhook := &Webhook{
+				Handler: handler,
+				log:     logf.RuntimeLog.WithName("webhook"),
+			}
+
+			expected := []byte(`{"response":{"uid":"","allowed":false,"status":{"metadata":{},"message":"unsupported AdmissionReview version \"admission.k8s.io/v2\"","code":400}}}
+`)
+			webhook.ServeHTTP(respRecorder, req)
+			Expect(respRecorder.Body.Bytes()).To(Equal(expected))
+			Expect(handler.invoked).To(BeFalse())
+		})
+
+		It("should cancel the context of the handler once the requested timeout elapses", func() {
+			req := &http.Request{
+				URL:    &url.URL{RawQuery: "timeout=10ms"},
+				Header: http.Header{"Content-Type": []string{"application/json"}},
+				Body:   nopCloser{Reader: bytes.NewBufferString(`{"request":{}}`)},
+			}
+			webhook := &Webhook{
+				Handler: &fakeHandler{
+					fn: func(ctx context.Context, req Request) Response {
+						<-ctx.Done()
+						return Denied(ctx.Err().Error())
+					},
+				},
+				log: logf.RuntimeLog.WithName("webhook"),
+			}
+
+			expected := []byte(`{"response":{"uid":"","allowed":false,"status":{"metadata":{},"reason":"context deadline exceeded","code":403}}}
+`)
+			webhook.ServeHTTP(respRecorder, req)
+			Expect(respRecorder.Body.Bytes()).To(Equal(expected))
+		})
+
+		It("should return bad-request when given an invalid timeout", func() {
+			req := &http.Request{
+				URL:    &url.URL{RawQuery: "timeout=soon"},
+				Header: http.Header{"Content-Type": []string{"application/json"}},
+				Body:   nopCloser{Reader: bytes.NewBufferString(`{"request":{}}`)},
+			}
+			handler := &fakeHandler{}
+			webhook := &Webhook{
+				Handler: handler,
+				log:     logf.RuntimeLog.WithName("webhook"),
+			}
+
+			webhook.ServeHTTP(respRecorder, req)
+			Expect(respRecorder.Body.String()).To(ContainSubstring(`invalid timeout \"soon\"`))
+			Expect(respRecorder.Body.String()).To(ContainSubstring(`"code":400`))
+			Expect(handler.invoked).To(BeFalse())
+		})
+
 		It("should present the Context from the HTTP request, if any", func() {
 			req := &http.Request{
 				Header: http.Header{"Content-Type": []string{"application/json"}},
@@ -155,7 +270,7 @@ func (h *fakeHandler) Handle(ctx context.Context, req Request) Response {
 	if h.fn != nil {
 		return h.fn(ctx, req)
 	}
-	return Response{AdmissionResponse: admissionv1beta1.AdmissionResponse{
+	return Response{AdmissionResponse: admissionv1.AdmissionResponse{
 		Allowed: true,
 	}}
 }
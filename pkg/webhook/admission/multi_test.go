@@ -23,14 +23,14 @@ import (
 	. "github.com/onsi/gomega"
 
 	"gomodules.xyz/jsonpatch/v2"
-	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
 )
 
 var _ = Describe("Multi-Handler Admission Webhooks", func() {
 	alwaysAllow := &fakeHandler{
 		fn: func(ctx context.Context, req Request) Response {
 			return Response{
-				AdmissionResponse: admissionv1beta1.AdmissionResponse{
+				AdmissionResponse: admissionv1.AdmissionResponse{
 					Allowed: true,
 				},
 			}
@@ -39,7 +39,7 @@ var _ = Describe("Multi-Handler Admission Webhooks", func() {
 	alwaysDeny := &fakeHandler{
 		fn: func(ctx context.Context, req Request) Response {
 			return Response{
-				AdmissionResponse: admissionv1beta1.AdmissionResponse{
+				AdmissionResponse: admissionv1.AdmissionResponse{
 					Allowed: false,
 				},
 			}
@@ -66,6 +66,37 @@ var _ = Describe("Multi-Handler Admission Webhooks", func() {
 		})
 	})
 
+	Context("with handlers that warn", func() {
+		warnWith := func(allowed bool, warning string) Handler {
+			return &fakeHandler{
+				fn: func(ctx context.Context, req Request) Response {
+					return ValidationResponse(allowed, "").WithWarnings(warning)
+				},
+			}
+		}
+
+		It("should collect the warnings of all the validating handlers", func() {
+			handler := MultiValidatingHandler(warnWith(true, "first"), warnWith(true, "second"))
+			resp := handler.Handle(context.Background(), Request{})
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Warnings).To(Equal(Warnings{"first", "second"}))
+		})
+
+		It("should keep the warnings of the handlers called before one denies the request", func() {
+			handler := MultiValidatingHandler(warnWith(true, "first"), warnWith(false, "second"), warnWith(true, "third"))
+			resp := handler.Handle(context.Background(), Request{})
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(resp.Warnings).To(ConsistOf("first", "second"))
+		})
+
+		It("should collect the warnings of all the mutating handlers", func() {
+			handler := MultiMutatingHandler(warnWith(true, "first"), warnWith(true, "second"))
+			resp := handler.Handle(context.Background(), Request{})
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Warnings).To(Equal(Warnings{"first", "second"}))
+		})
+	})
+
 	Context("with mutating handlers", func() {
 		patcher1 := &fakeHandler{
 			fn: func(ctx context.Context, req Request) Response {
@@ -82,9 +113,9 @@ var _ = Describe("Multi-Handler Admission Webhooks", func() {
 							Value:     "2",
 						},
 					},
-					AdmissionResponse: admissionv1beta1.AdmissionResponse{
+					AdmissionResponse: admissionv1.AdmissionResponse{
 						Allowed:   true,
-						PatchType: func() *admissionv1beta1.PatchType { pt := admissionv1beta1.PatchTypeJSONPatch; return &pt }(),
+						PatchType: func() *admissionv1.PatchType { pt := admissionv1.PatchTypeJSONPatch; return &pt }(),
 					},
 				}
 			},
@@ -99,9 +130,9 @@ var _ = Describe("Multi-Handler Admission Webhooks", func() {
 							Value:     "world",
 						},
 					},
-					AdmissionResponse: admissionv1beta1.AdmissionResponse{
+					AdmissionResponse: admissionv1.AdmissionResponse{
 						Allowed:   true,
-						PatchType: func() *admissionv1beta1.PatchType { pt := admissionv1beta1.PatchTypeJSONPatch; return &pt }(),
+						PatchType: func() *admissionv1.PatchType { pt := admissionv1.PatchTypeJSONPatch; return &pt }(),
 					},
 				}
 			},
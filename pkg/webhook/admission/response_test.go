@@ -24,7 +24,7 @@ import (
 	. "github.com/onsi/gomega"
 	"gomodules.xyz/jsonpatch/v2"
 
-	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -33,7 +33,7 @@ var _ = Describe("Admission Webhook Response Helpers", func() {
 		It("should return an 'allowed' response", func() {
 			Expect(Allowed("")).To(Equal(
 				Response{
-					AdmissionResponse: admissionv1beta1.AdmissionResponse{
+					AdmissionResponse: admissionv1.AdmissionResponse{
 						Allowed: true,
 						Result: &metav1.Status{
 							Code: http.StatusOK,
@@ -46,7 +46,7 @@ var _ = Describe("Admission Webhook Response Helpers", func() {
 		It("should populate a status with a reason when a reason is given", func() {
 			Expect(Allowed("acceptable")).To(Equal(
 				Response{
-					AdmissionResponse: admissionv1beta1.AdmissionResponse{
+					AdmissionResponse: admissionv1.AdmissionResponse{
 						Allowed: true,
 						Result: &metav1.Status{
 							Code:   http.StatusOK,
@@ -62,7 +62,7 @@ var _ = Describe("Admission Webhook Response Helpers", func() {
 		It("should return a 'not allowed' response", func() {
 			Expect(Denied("")).To(Equal(
 				Response{
-					AdmissionResponse: admissionv1beta1.AdmissionResponse{
+					AdmissionResponse: admissionv1.AdmissionResponse{
 						Allowed: false,
 						Result: &metav1.Status{
 							Code: http.StatusForbidden,
@@ -75,7 +75,7 @@ var _ = Describe("Admission Webhook Response Helpers", func() {
 		It("should populate a status with a reason when a reason is given", func() {
 			Expect(Denied("UNACCEPTABLE!")).To(Equal(
 				Response{
-					AdmissionResponse: admissionv1beta1.AdmissionResponse{
+					AdmissionResponse: admissionv1.AdmissionResponse{
 						Allowed: false,
 						Result: &metav1.Status{
 							Code:   http.StatusForbidden,
@@ -102,7 +102,7 @@ var _ = Describe("Admission Webhook Response Helpers", func() {
 		It("should return an 'allowed' response with the given patches", func() {
 			Expect(Patched("", ops...)).To(Equal(
 				Response{
-					AdmissionResponse: admissionv1beta1.AdmissionResponse{
+					AdmissionResponse: admissionv1.AdmissionResponse{
 						Allowed: true,
 						Result: &metav1.Status{
 							Code: http.StatusOK,
@@ -115,7 +115,7 @@ var _ = Describe("Admission Webhook Response Helpers", func() {
 		It("should populate a status with a reason when a reason is given", func() {
 			Expect(Patched("some changes", ops...)).To(Equal(
 				Response{
-					AdmissionResponse: admissionv1beta1.AdmissionResponse{
+					AdmissionResponse: admissionv1.AdmissionResponse{
 						Allowed: true,
 						Result: &metav1.Status{
 							Code:   http.StatusOK,
@@ -132,7 +132,7 @@ var _ = Describe("Admission Webhook Response Helpers", func() {
 		It("should return a denied response with an error", func() {
 			err := errors.New("this is an error")
 			expected := Response{
-				AdmissionResponse: admissionv1beta1.AdmissionResponse{
+				AdmissionResponse: admissionv1.AdmissionResponse{
 					Allowed: false,
 					Result: &metav1.Status{
 						Code:    http.StatusBadRequest,
@@ -150,7 +150,7 @@ var _ = Describe("Admission Webhook Response Helpers", func() {
 			By("checking that a message is populated for 'allowed' responses")
 			Expect(ValidationResponse(true, "acceptable")).To(Equal(
 				Response{
-					AdmissionResponse: admissionv1beta1.AdmissionResponse{
+					AdmissionResponse: admissionv1.AdmissionResponse{
 						Allowed: true,
 						Result: &metav1.Status{
 							Code:   http.StatusOK,
@@ -163,7 +163,7 @@ var _ = Describe("Admission Webhook Response Helpers", func() {
 			By("checking that a message is populated for 'denied' responses")
 			Expect(ValidationResponse(false, "UNACCEPTABLE!")).To(Equal(
 				Response{
-					AdmissionResponse: admissionv1beta1.AdmissionResponse{
+					AdmissionResponse: admissionv1.AdmissionResponse{
 						Allowed: false,
 						Result: &metav1.Status{
 							Code:   http.StatusForbidden,
@@ -178,7 +178,7 @@ var _ = Describe("Admission Webhook Response Helpers", func() {
 			By("checking that it returns an 'allowed' response when allowed is true")
 			Expect(ValidationResponse(true, "")).To(Equal(
 				Response{
-					AdmissionResponse: admissionv1beta1.AdmissionResponse{
+					AdmissionResponse: admissionv1.AdmissionResponse{
 						Allowed: true,
 						Result: &metav1.Status{
 							Code: http.StatusOK,
@@ -190,7 +190,7 @@ var _ = Describe("Admission Webhook Response Helpers", func() {
 			By("checking that it returns an 'denied' response when allowed is false")
 			Expect(ValidationResponse(false, "")).To(Equal(
 				Response{
-					AdmissionResponse: admissionv1beta1.AdmissionResponse{
+					AdmissionResponse: admissionv1.AdmissionResponse{
 						Allowed: false,
 						Result: &metav1.Status{
 							Code: http.StatusForbidden,
@@ -207,13 +207,37 @@ var _ = Describe("Admission Webhook Response Helpers", func() {
 				Patches: []jsonpatch.JsonPatchOperation{
 					{Operation: "replace", Path: "/a", Value: "bar"},
 				},
-				AdmissionResponse: admissionv1beta1.AdmissionResponse{
+				AdmissionResponse: admissionv1.AdmissionResponse{
 					Allowed:   true,
-					PatchType: func() *admissionv1beta1.PatchType { pt := admissionv1beta1.PatchTypeJSONPatch; return &pt }(),
+					PatchType: func() *admissionv1.PatchType { pt := admissionv1.PatchTypeJSONPatch; return &pt }(),
 				},
 			}
 			resp := PatchResponseFromRaw([]byte(`{"a": "foo"}`), []byte(`{"a": "bar"}`))
 			Expect(resp).To(Equal(expected))
 		})
 	})
+
+	Describe("WithWarnings", func() {
+		It("should add the warnings to those of the response", func() {
+			resp := Denied("UNACCEPTABLE!").WithWarnings("first")
+			Expect(resp.WithWarnings("second", "third")).To(Equal(
+				Response{
+					AdmissionResponse: admissionv1.AdmissionResponse{
+						Allowed: false,
+						Result: &metav1.Status{
+							Code:   http.StatusForbidden,
+							Reason: "UNACCEPTABLE!",
+						},
+					},
+					Warnings: Warnings{"first", "second", "third"},
+				},
+			))
+		})
+
+		It("should not modify the original response", func() {
+			resp := Allowed("").WithWarnings("first")
+			_ = resp.WithWarnings("second")
+			Expect(resp.Warnings).To(Equal(Warnings{"first"}))
+		})
+	})
 })
@@ -20,7 +20,7 @@ import (
 	"context"
 	"net/http"
 
-	"k8s.io/api/admission/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -60,19 +60,21 @@ func (h *validatingHandler) Handle(ctx context.Context, req Request) Response {
 
 	// Get the object in the request
 	obj := h.validator.DeepCopyObject().(Validator)
-	if req.Operation == v1beta1.Create {
+	if req.Operation == admissionv1.Create {
 		err := h.decoder.Decode(req, obj)
 		if err != nil {
 			return Errored(http.StatusBadRequest, err)
 		}
 
+		warnings := warningsFor(obj)
 		err = obj.ValidateCreate()
 		if err != nil {
-			return Denied(err.Error())
+			return Denied(err.Error()).WithWarnings(warnings...)
 		}
+		return Allowed("").WithWarnings(warnings...)
 	}
 
-	if req.Operation == v1beta1.Update {
+	if req.Operation == admissionv1.Update {
 		oldObj := obj.DeepCopyObject()
 
 		err := h.decoder.DecodeRaw(req.Object, obj)
@@ -84,13 +86,15 @@ func (h *validatingHandler) Handle(ctx context.Context, req Request) Response {
 			return Errored(http.StatusBadRequest, err)
 		}
 
+		warnings := warningsFor(obj)
 		err = obj.ValidateUpdate(oldObj)
 		if err != nil {
-			return Denied(err.Error())
+			return Denied(err.Error()).WithWarnings(warnings...)
 		}
+		return Allowed("").WithWarnings(warnings...)
 	}
 
-	if req.Operation == v1beta1.Delete {
+	if req.Operation == admissionv1.Delete {
 		// In reference to PR: https://github.com/kubernetes/kubernetes/pull/76346
 		// OldObject contains the object being deleted
 		err := h.decoder.DecodeRaw(req.OldObject, obj)
@@ -106,3 +110,11 @@ func (h *validatingHandler) Handle(ctx context.Context, req Request) Response {
 
 	return Allowed("")
 }
+
+// warningsFor returns the warnings about obj, if it implements Warner.
+func warningsFor(obj runtime.Object) Warnings {
+	if warner, ok := obj.(Warner); ok {
+		return warner.AdmissionWarnings()
+	}
+	return nil
+}
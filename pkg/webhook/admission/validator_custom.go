@@ -0,0 +1,143 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+// CustomValidator defines functions for validating an operation on objects
+// of a given type.  Unlike Validator, it is implemented by a separate type,
+// which can have dependencies (such as a client) injected, and receives the
+// context of the request, from which the admission Request can be retrieved
+// with RequestFromContext.
+type CustomValidator interface {
+	ValidateCreate(ctx context.Context, obj runtime.Object) error
+	ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error
+	ValidateDelete(ctx context.Context, obj runtime.Object) error
+}
+
+// WithCustomValidator creates a new Webhook for validating objects of the
+// type of obj with the given CustomValidator.
+func WithCustomValidator(obj runtime.Object, validator CustomValidator) *Webhook {
+	return &Webhook{
+		Handler: &validatorForType{object: obj, validator: validator},
+	}
+}
+
+type validatorForType struct {
+	object    runtime.Object
+	validator CustomValidator
+	decoder   *Decoder
+}
+
+var _ DecoderInjector = &validatorForType{}
+var _ inject.Injector = &validatorForType{}
+
+// InjectDecoder injects the decoder into a validatorForType.
+func (h *validatorForType) InjectDecoder(d *Decoder) error {
+	h.decoder = d
+	return nil
+}
+
+// InjectFunc injects dependencies into the validator.
+func (h *validatorForType) InjectFunc(f inject.Func) error {
+	return f(h.validator)
+}
+
+// Handle handles admission requests.
+func (h *validatorForType) Handle(ctx context.Context, req Request) Response {
+	if h.validator == nil {
+		panic("validator should never be nil")
+	}
+	if h.object == nil {
+		panic("object should never be nil")
+	}
+
+	ctx = NewContextWithRequest(ctx, req)
+
+	// Get the object in the request
+	obj := h.object.DeepCopyObject()
+
+	var warnings Warnings
+	warner, canWarn := h.validator.(CustomWarner)
+
+	var err error
+	switch req.Operation {
+	case admissionv1.Create:
+		if err := h.decoder.Decode(req, obj); err != nil {
+			return Errored(http.StatusBadRequest, err)
+		}
+
+		if canWarn {
+			warnings = warner.AdmissionWarnings(ctx, obj)
+		}
+		err = h.validator.ValidateCreate(ctx, obj)
+	case admissionv1.Update:
+		oldObj := obj.DeepCopyObject()
+		if err := h.decoder.DecodeRaw(req.Object, obj); err != nil {
+			return Errored(http.StatusBadRequest, err)
+		}
+		if err := h.decoder.DecodeRaw(req.OldObject, oldObj); err != nil {
+			return Errored(http.StatusBadRequest, err)
+		}
+
+		if canWarn {
+			warnings = warner.AdmissionWarnings(ctx, obj)
+		}
+		err = h.validator.ValidateUpdate(ctx, oldObj, obj)
+	case admissionv1.Delete:
+		// In reference to PR: https://github.com/kubernetes/kubernetes/pull/76346
+		// OldObject contains the object being deleted
+		if err := h.decoder.DecodeRaw(req.OldObject, obj); err != nil {
+			return Errored(http.StatusBadRequest, err)
+		}
+
+		err = h.validator.ValidateDelete(ctx, obj)
+	}
+
+	if err != nil {
+		return Denied(err.Error()).WithWarnings(warnings...)
+	}
+	return Allowed("").WithWarnings(warnings...)
+}
+
+// requestContextKey is the key of the admission Request in the contexts
+// passed to CustomValidators and CustomDefaulters.
+type requestContextKey struct{}
+
+// RequestFromContext returns the admission Request carried by ctx, such as the
+// one passed to a CustomValidator or CustomDefaulter.
+func RequestFromContext(ctx context.Context) (Request, error) {
+	if req, ok := ctx.Value(requestContextKey{}).(Request); ok {
+		return req, nil
+	}
+	return Request{}, errors.New("admission.Request not found in context")
+}
+
+// NewContextWithRequest returns a new context, derived from ctx, which carries
+// the given admission Request.
+func NewContextWithRequest(ctx context.Context, req Request) context.Context {
+	return context.WithValue(ctx, requestContextKey{}, req)
+}
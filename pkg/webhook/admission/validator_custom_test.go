@@ -0,0 +1,193 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+var _ = Describe("CustomValidator", func() {
+	var webhook *Webhook
+	var validator *podValidator
+
+	BeforeEach(func() {
+		validator = &podValidator{}
+		webhook = WithCustomValidator(&corev1.Pod{}, validator)
+		webhook.log = logf.RuntimeLog.WithName("webhook")
+
+		By("injecting a scheme and a client")
+		Expect(webhook.InjectScheme(scheme.Scheme)).To(Succeed())
+		c := fake.NewFakeClientWithScheme(scheme.Scheme, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "taken", Namespace: "default"},
+		})
+		Expect(webhook.InjectFunc(func(i interface{}) error {
+			_, err := inject.ClientInto(c, i)
+			return err
+		})).To(Succeed())
+		Expect(validator.client).NotTo(BeNil())
+	})
+
+	podRaw := func(name, image string) runtime.RawExtension {
+		return runtime.RawExtension{Raw: []byte(fmt.Sprintf(
+			`{"apiVersion":"v1","kind":"Pod","metadata":{"name":%q,"namespace":"default"},"spec":{"containers":[{"name":"c","image":%q}]}}`,
+			name, image))}
+	}
+
+	It("should validate creates with the request available from the context", func() {
+		resp := webhook.Handle(context.Background(), Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    podRaw("foo", "bar:v1"),
+			UserInfo:  authenticationv1.UserInfo{Username: "alice"},
+		}})
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(validator.lastUser).To(Equal("alice"))
+	})
+
+	It("should be able to look up other objects with the injected client", func() {
+		resp := webhook.Handle(context.Background(), Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    podRaw("taken", "bar:v1"),
+		}})
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(string(resp.Result.Reason)).To(ContainSubstring("name is reserved"))
+	})
+
+	It("should pass both the old and the new object to validate updates", func() {
+		resp := webhook.Handle(context.Background(), Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update,
+			Object:    podRaw("foo", "bar:v2"),
+			OldObject: podRaw("foo", "bar:v1"),
+		}})
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(string(resp.Result.Reason)).To(ContainSubstring("image changed from bar:v1 to bar:v2"))
+	})
+
+	It("should add the warnings of the validator to the response, whether or not the request is allowed", func() {
+		resp := webhook.Handle(context.Background(), Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    podRaw("foo", "bar:latest"),
+		}})
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Warnings).To(ConsistOf("image bar:latest uses the latest tag"))
+
+		resp = webhook.Handle(context.Background(), Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update,
+			Object:    podRaw("foo", "bar:latest"),
+			OldObject: podRaw("foo", "bar:v1"),
+		}})
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(resp.Warnings).To(ConsistOf("image bar:latest uses the latest tag"))
+	})
+
+	It("should pass the object being deleted to validate deletes", func() {
+		resp := webhook.Handle(context.Background(), Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Delete,
+			OldObject: podRaw("undeletable", "bar:v1"),
+		}})
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(string(resp.Result.Reason)).To(ContainSubstring("undeletable cannot be deleted"))
+	})
+})
+
+var _ = Describe("RequestFromContext", func() {
+	It("should return an error if the context carries no request", func() {
+		_, err := RequestFromContext(context.Background())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return the request carried by the context", func() {
+		req := Request{AdmissionRequest: admissionv1.AdmissionRequest{UID: "foo"}}
+		got, err := RequestFromContext(NewContextWithRequest(context.Background(), req))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(req))
+	})
+})
+
+// podValidator rejects pods whose name is reserved by a ConfigMap, image
+// changes, and deleting pods named "undeletable".
+type podValidator struct {
+	client   client.Client
+	lastUser string
+}
+
+var _ CustomValidator = &podValidator{}
+var _ CustomWarner = &podValidator{}
+
+func (v *podValidator) InjectClient(c client.Client) error {
+	v.client = c
+	return nil
+}
+
+func (v *podValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	req, err := RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	v.lastUser = req.UserInfo.Username
+
+	pod := obj.(*corev1.Pod)
+	err = v.client.Get(ctx, client.ObjectKey{Namespace: pod.Namespace, Name: pod.Name}, &corev1.ConfigMap{})
+	if err == nil {
+		return fmt.Errorf("name is reserved")
+	}
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (v *podValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	oldImage := oldObj.(*corev1.Pod).Spec.Containers[0].Image
+	newImage := newObj.(*corev1.Pod).Spec.Containers[0].Image
+	if oldImage != newImage {
+		return fmt.Errorf("image changed from %s to %s", oldImage, newImage)
+	}
+	return nil
+}
+
+func (v *podValidator) AdmissionWarnings(ctx context.Context, obj runtime.Object) Warnings {
+	if image := obj.(*corev1.Pod).Spec.Containers[0].Image; strings.HasSuffix(image, ":latest") {
+		return Warnings{"image " + image + " uses the latest tag"}
+	}
+	return nil
+}
+
+func (v *podValidator) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	if name := obj.(*corev1.Pod).Name; name == "undeletable" {
+		return errors.New(name + " cannot be deleted")
+	}
+	return nil
+}
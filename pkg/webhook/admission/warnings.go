@@ -0,0 +1,43 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Warnings are messages to be shown to the user making an admission request,
+// such as notices about the use of deprecated fields.
+type Warnings []string
+
+// Warner can be implemented by Validators and Defaulters to return warnings
+// about the object of a request, which are shown to the user whether or not
+// the request is allowed.  For updates, it is called on the new object; it is
+// not called for deletions.
+type Warner interface {
+	AdmissionWarnings() Warnings
+}
+
+// CustomWarner can be implemented by CustomValidators and CustomDefaulters
+// to return warnings about the object of a request, which are shown to the
+// user whether or not the request is allowed.  For updates, it is called
+// with the new object; it is not called for deletions.
+type CustomWarner interface {
+	AdmissionWarnings(ctx context.Context, obj runtime.Object) Warnings
+}
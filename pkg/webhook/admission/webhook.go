@@ -19,16 +19,19 @@ package admission
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"runtime/debug"
 
 	"github.com/go-logr/logr"
 	"gomodules.xyz/jsonpatch/v2"
-	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/json"
 
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/internal/metrics"
 )
 
 var (
@@ -41,7 +44,7 @@ var (
 // name, namespace), as well as the operation in question
 // (e.g. Get, Create, etc), and the object itself.
 type Request struct {
-	admissionv1beta1.AdmissionRequest
+	admissionv1.AdmissionRequest
 }
 
 // Response is the output of an admission handler.
@@ -57,7 +60,13 @@ type Response struct {
 	Patches []jsonpatch.JsonPatchOperation
 	// AdmissionResponse is the raw admission response.
 	// The Patch field in it will be overwritten by the listed patches.
-	admissionv1beta1.AdmissionResponse
+	admissionv1.AdmissionResponse
+	// Warnings are messages shown to the user by the API server, whether or
+	// not the request is allowed.  They are only supported by API servers
+	// from Kubernetes 1.19 on, and ignored by older ones.
+	// Warnings are kept here since the AdmissionResponse type of the version
+	// of the API we build against predates them.
+	Warnings Warnings
 }
 
 // Complete populates any fields that are yet to be set in
@@ -84,7 +93,7 @@ func (r *Response) Complete(req Request) error {
 	if err != nil {
 		return err
 	}
-	patchType := admissionv1beta1.PatchTypeJSONPatch
+	patchType := admissionv1.PatchTypeJSONPatch
 	r.PatchType = &patchType
 
 	return nil
@@ -131,7 +140,20 @@ func (w *Webhook) InjectLogger(l logr.Logger) error {
 // If the webhook is mutating type, it delegates the AdmissionRequest to each handler and merge the patches.
 // If the webhook is validating type, it delegates the AdmissionRequest to each handler and
 // deny the request if anyone denies.
-func (w *Webhook) Handle(ctx context.Context, req Request) Response {
+// If the handler panics, the request is answered with an Errored response.
+func (w *Webhook) Handle(ctx context.Context, req Request) (response Response) {
+	defer func() {
+		if r := recover(); r != nil {
+			err := fmt.Errorf("panic: %v [recovered]", r)
+			w.log.Error(err, "observed a panic while handling an admission request", "UID", req.UID, "kind", req.Kind, "stacktrace", string(debug.Stack()))
+			metrics.WebhookPanics.WithLabelValues("admission").Inc()
+
+			response = Errored(http.StatusInternalServerError, err)
+			// the API server expects the UID of the request back
+			response.UID = req.UID
+		}
+	}()
+
 	resp := w.Handler.Handle(ctx, req)
 	if err := resp.Complete(req); err != nil {
 		w.log.Error(err, "unable to encode response")
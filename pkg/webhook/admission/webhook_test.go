@@ -23,14 +23,16 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"gomodules.xyz/jsonpatch/v2"
-	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	machinerytypes "k8s.io/apimachinery/pkg/types"
 
 	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/internal/metrics"
 )
 
 var _ = Describe("Admission Webhooks", func() {
@@ -38,7 +40,7 @@ var _ = Describe("Admission Webhooks", func() {
 		handler := &fakeHandler{
 			fn: func(ctx context.Context, req Request) Response {
 				return Response{
-					AdmissionResponse: admissionv1beta1.AdmissionResponse{
+					AdmissionResponse: admissionv1.AdmissionResponse{
 						Allowed: true,
 					},
 				}
@@ -68,12 +70,33 @@ var _ = Describe("Admission Webhooks", func() {
 		webhook := allowHandler()
 
 		By("invoking the webhook")
-		resp := webhook.Handle(context.Background(), Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{UID: "foobar"}})
+		resp := webhook.Handle(context.Background(), Request{AdmissionRequest: admissionv1.AdmissionRequest{UID: "foobar"}})
 
 		By("checking that the response share's the request's UID")
 		Expect(resp.UID).To(Equal(machinerytypes.UID("foobar")))
 	})
 
+	It("should recover from a panicking handler with an errored response", func() {
+		By("setting up a webhook with a panicking handler")
+		webhook := &Webhook{
+			Handler: HandlerFunc(func(ctx context.Context, req Request) Response {
+				panic("boom")
+			}),
+			log: logf.RuntimeLog.WithName("webhook"),
+		}
+		panics := testutil.ToFloat64(metrics.WebhookPanics.WithLabelValues("admission"))
+
+		By("invoking the webhook")
+		resp := webhook.Handle(context.Background(), Request{AdmissionRequest: admissionv1.AdmissionRequest{UID: "foobar"}})
+
+		By("checking that the request was answered with an error, and counted")
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(resp.UID).To(Equal(machinerytypes.UID("foobar")))
+		Expect(resp.Result.Code).To(Equal(int32(http.StatusInternalServerError)))
+		Expect(resp.Result.Message).To(ContainSubstring("boom"))
+		Expect(testutil.ToFloat64(metrics.WebhookPanics.WithLabelValues("admission"))).To(Equal(panics + 1))
+	})
+
 	It("should populate the status on a response if one is not provided", func() {
 		By("setting up a webhook")
 		webhook := allowHandler()
@@ -90,7 +113,7 @@ var _ = Describe("Admission Webhooks", func() {
 		webhook := &Webhook{
 			Handler: HandlerFunc(func(ctx context.Context, req Request) Response {
 				return Response{
-					AdmissionResponse: admissionv1beta1.AdmissionResponse{
+					AdmissionResponse: admissionv1.AdmissionResponse{
 						Allowed: true,
 						Result:  &metav1.Status{Message: "Ground Control to Major Tom"},
 					},
@@ -120,7 +143,7 @@ var _ = Describe("Admission Webhooks", func() {
 		resp := webhook.Handle(context.Background(), Request{})
 
 		By("checking that a JSON patch is populated on the response")
-		patchType := admissionv1beta1.PatchTypeJSONPatch
+		patchType := admissionv1.PatchTypeJSONPatch
 		Expect(resp.PatchType).To(Equal(&patchType))
 		Expect(resp.Patch).To(Equal([]byte(`[{"op":"add","path":"/a","value":2},{"op":"replace","path":"/b","value":4}]`)))
 	})
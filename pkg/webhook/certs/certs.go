@@ -0,0 +1,170 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// keyPair is a certificate along with its private key.
+type keyPair struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newCA generates a self-signed CA certificate valid for the given duration.
+func newCA(commonName string, validity time.Duration) (*keyPair, error) {
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return newKeyPair(template, nil, validity)
+}
+
+// newServingCert generates a serving certificate for the given DNS names,
+// signed by the given CA and valid for the given duration.
+func newServingCert(ca *keyPair, dnsNames []string, validity time.Duration) (*keyPair, error) {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:    dnsNames,
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	return newKeyPair(template, ca, validity)
+}
+
+// newKeyPair generates a key and a certificate from the given template,
+// signed by the given CA, or self-signed if the CA is nil.
+func newKeyPair(template *x509.Certificate, ca *keyPair, validity time.Duration) (*keyPair, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate a private key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate a serial number: %w", err)
+	}
+
+	now := time.Now()
+	template.SerialNumber = serial
+	// allow for some clock skew between the API servers and us
+	template.NotBefore = now.Add(-5 * time.Minute).UTC()
+	template.NotAfter = now.Add(validity).UTC()
+
+	parent, signer := template, crypto.Signer(key)
+	if ca != nil {
+		parent, signer = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), signer)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create a certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &keyPair{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}, nil
+}
+
+// parseKeyPair parses a PEM-encoded certificate and EC private key.
+func parseKeyPair(certPEM, keyPEM []byte) (*keyPair, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil || certBlock.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM-encoded certificate found")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil || keyBlock.Type != "EC PRIVATE KEY" {
+		return nil, errors.New("no PEM-encoded EC private key found")
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &keyPair{cert: cert, key: key, certPEM: certPEM, keyPEM: keyPEM}, nil
+}
+
+// parseCert parses a PEM-encoded certificate.
+func parseCert(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM-encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// needsRenewal returns whether the certificate expires within a third of its
+// total validity from now.
+func needsRenewal(cert *x509.Certificate, now time.Time) bool {
+	validity := cert.NotAfter.Sub(cert.NotBefore)
+	return now.Add(validity / 3).After(cert.NotAfter)
+}
+
+// servesFor returns whether the serving certificate is signed by the CA and
+// valid for exactly the given DNS names.
+func servesFor(serving *keyPair, ca *keyPair, dnsNames []string) bool {
+	if !bytes.Equal(serving.cert.RawIssuer, ca.cert.RawSubject) {
+		return false
+	}
+	if err := serving.cert.CheckSignatureFrom(ca.cert); err != nil {
+		return false
+	}
+	if len(serving.cert.DNSNames) != len(dnsNames) {
+		return false
+	}
+	for i, name := range dnsNames {
+		if serving.cert.DNSNames[i] != name {
+			return false
+		}
+	}
+	return true
+}
+
+// base64Encode encodes data the way []byte fields are serialized.
+func base64Encode(data []byte) string {
+	return base64.StdEncoding.EncodeToString(data)
+}
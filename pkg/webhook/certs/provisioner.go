@@ -0,0 +1,506 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package certs provides a Provisioner which generates and rotates the
+// serving certificate of a webhook server, without relying on an external
+// certificate manager.
+package certs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+var log = logf.RuntimeLog.WithName("webhook").WithName("certs")
+
+const (
+	// CACertKey is the key of the CA certificate in the Secret.
+	CACertKey = "ca.crt"
+	// CAKeyKey is the key of the CA private key in the Secret.
+	CAKeyKey = "ca.key"
+	// PreviousCACertKey is the key of the CA certificate replaced by the
+	// current one in the Secret, which is kept in the CA bundle until it
+	// expires.
+	PreviousCACertKey = "previous-ca.crt"
+	// CertKey is the key of the serving certificate in the Secret.
+	CertKey = corev1.TLSCertKey
+	// KeyKey is the key of the serving private key in the Secret.
+	KeyKey = corev1.TLSPrivateKeyKey
+
+	defaultCAValidity    = 10 * 365 * 24 * time.Hour
+	defaultCertValidity  = 365 * 24 * time.Hour
+	defaultCheckInterval = time.Hour
+	retryInterval        = 10 * time.Second
+	maxSecretAttempts    = 5
+)
+
+var (
+	mutatingWebhookConfigurationGVK   = schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "MutatingWebhookConfiguration"}
+	validatingWebhookConfigurationGVK = schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "ValidatingWebhookConfiguration"}
+	crdGVK                            = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+)
+
+// Provisioner is a Runnable which provisions the serving certificate of a
+// webhook server.  It generates a CA and a serving certificate signed by it,
+// stores them in a Secret shared by all the replicas of the webhook, and
+// writes the serving certificate and key to CertDir, where the webhook server
+// loads them from.  The CA certificate is set as the caBundle of the given
+// webhook configurations and CRD conversion webhooks.
+//
+// The certificates are renewed once two thirds of their validity has elapsed.
+// When the CA is renewed, the previous CA stays in the CA bundle until it
+// expires, so that serving certificates signed by either are trusted.
+//
+// The Provisioner needs permission to get, create and update the Secret,
+// and to get and update the webhook configurations (admissionregistration.k8s.io/v1)
+// and CRDs (apiextensions.k8s.io/v1).
+type Provisioner struct {
+	// Client is used to read and write the Secret, webhook configurations and
+	// CRDs.  If nil, a client reading from the API server directly is created
+	// from the config injected by the manager.
+	Client client.Client
+
+	// Secret is the namespace and name of the Secret holding the certificates.
+	Secret types.NamespacedName
+
+	// CertDir is the directory the serving certificate and key are written to,
+	// as tls.crt and tls.key.  It should be the CertDir of the webhook server.
+	CertDir string
+
+	// DNSNames are the names the serving certificate is valid for, typically
+	// <service name>.<service namespace>.svc.
+	DNSNames []string
+
+	// MutatingWebhookConfigurations are the names of the
+	// MutatingWebhookConfigurations whose webhooks are given the CA bundle.
+	MutatingWebhookConfigurations []string
+
+	// ValidatingWebhookConfigurations are the names of the
+	// ValidatingWebhookConfigurations whose webhooks are given the CA bundle.
+	ValidatingWebhookConfigurations []string
+
+	// CRDs are the names of the CustomResourceDefinitions whose conversion
+	// webhooks are given the CA bundle.
+	CRDs []string
+
+	// CAValidity is how long generated CA certificates are valid for.
+	// Defaults to 10 years.
+	CAValidity time.Duration
+
+	// CertValidity is how long generated serving certificates are valid for.
+	// Defaults to 1 year.
+	CertValidity time.Duration
+
+	// CheckInterval is how often the certificates are checked for renewal,
+	// and the CA bundles are checked.  Defaults to 1 hour.
+	CheckInterval time.Duration
+
+	config *rest.Config
+
+	readyOnce      sync.Once
+	readyCloseOnce sync.Once
+	ready          chan struct{}
+}
+
+var _ manager.Runnable = &Provisioner{}
+var _ manager.LeaderElectionRunnable = &Provisioner{}
+var _ inject.Config = &Provisioner{}
+
+// AddToManager adds the Provisioner to the manager, and makes the manager's
+// webhook server wait for the serving certificate to be provisioned before
+// serving.  If CertDir is empty, it is set to the CertDir of the webhook
+// server, or to a new temporary directory if that is empty too.
+func (p *Provisioner) AddToManager(mgr manager.Manager) error {
+	srv := mgr.GetWebhookServer()
+	if p.CertDir == "" {
+		p.CertDir = srv.CertDir
+	}
+	if p.CertDir == "" {
+		dir, err := ioutil.TempDir("", "webhook-certs")
+		if err != nil {
+			return err
+		}
+		p.CertDir = dir
+	}
+	srv.CertDir = p.CertDir
+	srv.CertsReady = p.Ready()
+	return mgr.Add(p)
+}
+
+// InjectConfig injects the config used to create a client if none is set.
+func (p *Provisioner) InjectConfig(config *rest.Config) error {
+	p.config = config
+	return nil
+}
+
+// NeedLeaderElection implements LeaderElectionRunnable.  Every replica needs
+// the serving certificate, so the Provisioner runs regardless of leader
+// election.
+func (p *Provisioner) NeedLeaderElection() bool {
+	return false
+}
+
+// Ready returns a channel which is closed once the serving certificate has
+// been written to CertDir for the first time.
+func (p *Provisioner) Ready() <-chan struct{} {
+	p.readyOnce.Do(func() {
+		p.ready = make(chan struct{})
+	})
+	return p.ready
+}
+
+// Start provisions the certificates, and keeps them up to date until the stop
+// channel is closed.
+func (p *Provisioner) Start(stop <-chan struct{}) error {
+	if err := p.setDefaults(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		interval := p.CheckInterval
+		if err := p.Provision(ctx); err != nil {
+			log.Error(err, "unable to provision the webhook certificates", "secret", p.Secret)
+			interval = retryInterval
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (p *Provisioner) setDefaults() error {
+	if p.Secret.Name == "" || p.Secret.Namespace == "" {
+		return errors.New("the namespace and name of the Secret holding the certificates must be set")
+	}
+	if p.CertDir == "" {
+		return errors.New("the directory to write the serving certificate to must be set")
+	}
+	if len(p.DNSNames) == 0 {
+		return errors.New("at least one DNS name for the serving certificate must be set")
+	}
+	if p.CAValidity <= 0 {
+		p.CAValidity = defaultCAValidity
+	}
+	if p.CertValidity <= 0 {
+		p.CertValidity = defaultCertValidity
+	}
+	if p.CheckInterval <= 0 {
+		p.CheckInterval = defaultCheckInterval
+	}
+	if p.Client == nil {
+		if p.config == nil {
+			return errors.New("either a client or a config to create one must be set")
+		}
+		c, err := client.New(p.config, client.Options{})
+		if err != nil {
+			return err
+		}
+		p.Client = c
+	}
+	return nil
+}
+
+// Provision makes sure the certificates in the Secret are valid and up to
+// date, sets the CA bundle of the webhooks, and writes the serving
+// certificate to CertDir.  It is called periodically by Start.
+func (p *Provisioner) Provision(ctx context.Context) error {
+	var secret *corev1.Secret
+	var err error
+	// replicas may race to create or update the Secret, in which case we use
+	// the certificates of the winner
+	for attempt := 0; attempt < maxSecretAttempts; attempt++ {
+		secret, err = p.syncSecret(ctx)
+		if !apierrors.IsAlreadyExists(err) && !apierrors.IsConflict(err) {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("unable to update the certificates in Secret %s: %w", p.Secret, err)
+	}
+
+	// Trust the certificates before serving them, so that a certificate
+	// signed by a new CA is never served while the API servers only know the
+	// previous one.
+	errs := p.injectCABundle(ctx, caBundle(secret.Data))
+	if len(errs) > 0 && p.servesPreviousCA(secret.Data) {
+		// keep serving the certificate signed by the previous CA until the new
+		// one is trusted by all the webhooks
+		log.Info("not serving the certificate signed by the new CA until the CA bundle is injected", "dir", p.CertDir)
+		return kerrors.NewAggregate(errs)
+	}
+	if err := p.writeCerts(secret.Data); err != nil {
+		errs = append(errs, fmt.Errorf("unable to write the serving certificate to %s: %w", p.CertDir, err))
+	} else {
+		p.markReady()
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+// syncSecret reads the Secret, creating it or renewing its certificates if
+// necessary.
+func (p *Provisioner) syncSecret(ctx context.Context) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	err := p.Client.Get(ctx, p.Secret, secret)
+	notFound := apierrors.IsNotFound(err)
+	if err != nil && !notFound {
+		return nil, err
+	}
+	if notFound {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: p.Secret.Namespace, Name: p.Secret.Name},
+			Type:       corev1.SecretTypeOpaque,
+		}
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+
+	changed, err := p.renewCerts(secret.Data, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case notFound:
+		log.Info("creating the webhook certificates", "secret", p.Secret)
+		err = p.Client.Create(ctx, secret)
+	case changed:
+		log.Info("renewing the webhook certificates", "secret", p.Secret)
+		err = p.Client.Update(ctx, secret)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// renewCerts generates the certificates in data which are missing, invalid
+// or close to expiring.  It returns whether data changed.
+func (p *Provisioner) renewCerts(data map[string][]byte, now time.Time) (bool, error) {
+	changed := false
+
+	ca, err := parseKeyPair(data[CACertKey], data[CAKeyKey])
+	if err != nil || needsRenewal(ca.cert, now) {
+		if err == nil {
+			data[PreviousCACertKey] = ca.certPEM
+		}
+		if ca, err = newCA(p.Secret.Name+"-ca", p.CAValidity); err != nil {
+			return false, err
+		}
+		data[CACertKey], data[CAKeyKey] = ca.certPEM, ca.keyPEM
+		changed = true
+	}
+
+	if previous, ok := data[PreviousCACertKey]; ok {
+		if cert, err := parseCert(previous); err != nil || now.After(cert.NotAfter) {
+			delete(data, PreviousCACertKey)
+			changed = true
+		}
+	}
+
+	serving, err := parseKeyPair(data[CertKey], data[KeyKey])
+	if err != nil || needsRenewal(serving.cert, now) || !servesFor(serving, ca, p.DNSNames) {
+		// never outlive the CA
+		validity := p.CertValidity
+		if caValidity := ca.cert.NotAfter.Sub(now); caValidity < validity {
+			validity = caValidity
+		}
+		if serving, err = newServingCert(ca, p.DNSNames, validity); err != nil {
+			return false, err
+		}
+		data[CertKey], data[KeyKey] = serving.certPEM, serving.keyPEM
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// caBundle returns the CA certificates to trust, the current CA first.
+func caBundle(data map[string][]byte) []byte {
+	bundle := append([]byte{}, data[CACertKey]...)
+	return append(bundle, data[PreviousCACertKey]...)
+}
+
+// writeCerts writes the serving certificate and key to CertDir, unless they
+// are already there.
+func (p *Provisioner) writeCerts(data map[string][]byte) error {
+	if err := os.MkdirAll(p.CertDir, 0700); err != nil {
+		return err
+	}
+	// write the key first, since the webhook server reloads both files when
+	// the certificate changes
+	for _, name := range []string{KeyKey, CertKey} {
+		path := filepath.Join(p.CertDir, name)
+		if current, err := ioutil.ReadFile(path); err == nil && bytes.Equal(current, data[name]) {
+			continue
+		}
+		if err := ioutil.WriteFile(path, data[name], 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// servesPreviousCA returns whether the serving certificate in CertDir is not signed
+// by the CA in data, i.e. the CA was renewed since it was written.
+func (p *Provisioner) servesPreviousCA(data map[string][]byte) bool {
+	certPEM, err := ioutil.ReadFile(filepath.Join(p.CertDir, CertKey))
+	if err != nil {
+		return false
+	}
+	serving, err := parseCert(certPEM)
+	if err != nil {
+		return false
+	}
+	ca, err := parseCert(data[CACertKey])
+	if err != nil {
+		return false
+	}
+	return serving.CheckSignatureFrom(ca) != nil
+}
+
+// markReady closes the channel returned by Ready, if it is not closed yet.
+func (p *Provisioner) markReady() {
+	p.Ready()
+	p.readyCloseOnce.Do(func() {
+		log.Info("the serving certificate is ready", "dir", p.CertDir)
+		close(p.ready)
+	})
+}
+
+// injectCABundle sets the CA bundle of all the configured webhooks.
+func (p *Provisioner) injectCABundle(ctx context.Context, bundle []byte) []error {
+	var errs []error
+	for _, name := range p.MutatingWebhookConfigurations {
+		if err := p.injectInto(ctx, mutatingWebhookConfigurationGVK, name, bundle, setWebhooksCABundle); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, name := range p.ValidatingWebhookConfigurations {
+		if err := p.injectInto(ctx, validatingWebhookConfigurationGVK, name, bundle, setWebhooksCABundle); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, name := range p.CRDs {
+		if err := p.injectInto(ctx, crdGVK, name, bundle, setConversionCABundle); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// injectInto sets the CA bundle of the given object with the given function,
+// updating the object if it changed.
+func (p *Provisioner) injectInto(ctx context.Context, gvk schema.GroupVersionKind, name string, bundle []byte,
+	setCABundle func(obj *unstructured.Unstructured, encodedBundle string) (bool, error)) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := p.Client.Get(ctx, client.ObjectKey{Name: name}, obj); err != nil {
+		return fmt.Errorf("unable to get %s %s: %w", gvk.Kind, name, err)
+	}
+	// []byte fields are serialized in base64
+	changed, err := setCABundle(obj, base64Encode(bundle))
+	if err != nil {
+		return fmt.Errorf("unable to set the CA bundle of %s %s: %w", gvk.Kind, name, err)
+	}
+	if !changed {
+		return nil
+	}
+	log.Info("updating the CA bundle", "kind", gvk.Kind, "name", name)
+	if err := p.Client.Update(ctx, obj); err != nil {
+		return fmt.Errorf("unable to update the CA bundle of %s %s: %w", gvk.Kind, name, err)
+	}
+	return nil
+}
+
+// setWebhooksCABundle sets the CA bundle of every webhook of a webhook
+// configuration.
+func setWebhooksCABundle(obj *unstructured.Unstructured, encodedBundle string) (bool, error) {
+	webhooks, _, err := unstructured.NestedSlice(obj.Object, "webhooks")
+	if err != nil {
+		return false, err
+	}
+	changed := false
+	for i, webhook := range webhooks {
+		webhook, ok := webhook.(map[string]interface{})
+		if !ok {
+			return false, fmt.Errorf("webhook %d is not an object", i)
+		}
+		current, _, _ := unstructured.NestedString(webhook, "clientConfig", "caBundle")
+		if current == encodedBundle {
+			continue
+		}
+		if err := unstructured.SetNestedField(webhook, encodedBundle, "clientConfig", "caBundle"); err != nil {
+			return false, err
+		}
+		webhooks[i] = webhook
+		changed = true
+	}
+	if !changed {
+		return false, nil
+	}
+	return true, unstructured.SetNestedSlice(obj.Object, webhooks, "webhooks")
+}
+
+// setConversionCABundle sets the CA bundle of the conversion webhook of a CRD.
+func setConversionCABundle(obj *unstructured.Unstructured, encodedBundle string) (bool, error) {
+	strategy, _, err := unstructured.NestedString(obj.Object, "spec", "conversion", "strategy")
+	if err != nil {
+		return false, err
+	}
+	if strategy != "Webhook" {
+		return false, fmt.Errorf("the conversion strategy is %q rather than Webhook", strategy)
+	}
+	path := []string{"spec", "conversion", "webhook", "clientConfig", "caBundle"}
+	current, _, _ := unstructured.NestedString(obj.Object, path...)
+	if current == encodedBundle {
+		return false, nil
+	}
+	return true, unstructured.SetNestedField(obj.Object, encodedBundle, path...)
+}
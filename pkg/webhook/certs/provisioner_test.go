@@ -0,0 +1,268 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Provisioner", func() {
+	var dir string
+	var c client.Client
+	var p *Provisioner
+	ctx := context.Background()
+	secretKey := types.NamespacedName{Namespace: "system", Name: "webhook-certs"}
+
+	newCRD := func(name, strategy string) *unstructured.Unstructured {
+		crd := &unstructured.Unstructured{}
+		crd.SetGroupVersionKind(crdGVK)
+		crd.SetName(name)
+		Expect(unstructured.SetNestedField(crd.Object, strategy, "spec", "conversion", "strategy")).To(Succeed())
+		return crd
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "webhook-certs")
+		Expect(err).NotTo(HaveOccurred())
+
+		c = fake.NewFakeClientWithScheme(scheme.Scheme,
+			&admissionregistrationv1.MutatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: "mutating"},
+				Webhooks:   []admissionregistrationv1.MutatingWebhook{{Name: "a.example.com"}, {Name: "b.example.com"}},
+			},
+			&admissionregistrationv1.ValidatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: "validating"},
+				Webhooks:   []admissionregistrationv1.ValidatingWebhook{{Name: "c.example.com"}},
+			},
+			newCRD("jobs.example.com", "Webhook"),
+			newCRD("pods.example.com", "None"),
+		)
+		p = &Provisioner{
+			Client:                          c,
+			Secret:                          secretKey,
+			CertDir:                         dir,
+			DNSNames:                        []string{"webhook.system.svc", "webhook.system.svc.cluster.local"},
+			MutatingWebhookConfigurations:   []string{"mutating"},
+			ValidatingWebhookConfigurations: []string{"validating"},
+			CRDs:                            []string{"jobs.example.com"},
+		}
+		Expect(p.setDefaults()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	getSecret := func() *corev1.Secret {
+		secret := &corev1.Secret{}
+		Expect(c.Get(ctx, secretKey, secret)).To(Succeed())
+		return secret
+	}
+
+	// verify checks that the serving certificate in CertDir is trusted by the
+	// given CA bundle for all the DNS names
+	verify := func(bundle []byte) {
+		certPEM, err := ioutil.ReadFile(filepath.Join(dir, CertKey))
+		Expect(err).NotTo(HaveOccurred())
+		keyPEM, err := ioutil.ReadFile(filepath.Join(dir, KeyKey))
+		Expect(err).NotTo(HaveOccurred())
+		serving, err := parseKeyPair(certPEM, keyPEM)
+		Expect(err).NotTo(HaveOccurred())
+
+		roots := x509.NewCertPool()
+		Expect(roots.AppendCertsFromPEM(bundle)).To(BeTrue())
+		for _, name := range p.DNSNames {
+			_, err := serving.cert.Verify(x509.VerifyOptions{DNSName: name, Roots: roots})
+			Expect(err).NotTo(HaveOccurred())
+		}
+	}
+
+	It("should generate the certificates, store them, write them and inject the CA bundle", func() {
+		Expect(p.Provision(ctx)).To(Succeed())
+		Expect(p.Ready()).To(BeClosed())
+
+		secret := getSecret()
+		Expect(secret.Data).To(HaveKey(CACertKey))
+		Expect(secret.Data).To(HaveKey(CAKeyKey))
+		Expect(secret.Data).NotTo(HaveKey(PreviousCACertKey))
+		verify(secret.Data[CACertKey])
+
+		encodedBundle := base64.StdEncoding.EncodeToString(secret.Data[CACertKey])
+
+		mutating := &admissionregistrationv1.MutatingWebhookConfiguration{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "mutating"}, mutating)).To(Succeed())
+		Expect(mutating.Webhooks).To(HaveLen(2))
+		for _, webhook := range mutating.Webhooks {
+			Expect(webhook.ClientConfig.CABundle).To(Equal(secret.Data[CACertKey]))
+		}
+
+		validating := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "validating"}, validating)).To(Succeed())
+		Expect(validating.Webhooks[0].ClientConfig.CABundle).To(Equal(secret.Data[CACertKey]))
+
+		crd := &unstructured.Unstructured{}
+		crd.SetGroupVersionKind(crdGVK)
+		Expect(c.Get(ctx, client.ObjectKey{Name: "jobs.example.com"}, crd)).To(Succeed())
+		crdBundle, _, err := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "caBundle")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(crdBundle).To(Equal(encodedBundle))
+	})
+
+	It("should leave up-to-date certificates alone", func() {
+		Expect(p.Provision(ctx)).To(Succeed())
+		before := getSecret()
+
+		Expect(p.Provision(ctx)).To(Succeed())
+		Expect(getSecret()).To(Equal(before))
+	})
+
+	It("should use the certificates stored by another replica", func() {
+		Expect(p.Provision(ctx)).To(Succeed())
+		secret := getSecret()
+
+		otherDir, err := ioutil.TempDir("", "webhook-certs")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(otherDir)
+		other := &Provisioner{
+			Client:   c,
+			Secret:   secretKey,
+			CertDir:  otherDir,
+			DNSNames: p.DNSNames,
+		}
+		Expect(other.setDefaults()).To(Succeed())
+		Expect(other.Provision(ctx)).To(Succeed())
+		Expect(getSecret()).To(Equal(secret))
+
+		written, err := ioutil.ReadFile(filepath.Join(otherDir, CertKey))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(written).To(Equal(secret.Data[CertKey]))
+	})
+
+	It("should renew the serving certificate when it gets close to expiring, or its names change", func() {
+		data := map[string][]byte{}
+		Expect(p.renewCerts(data, time.Now())).To(BeTrue())
+		ca, serving := data[CACertKey], data[CertKey]
+
+		By("keeping it while it is fresh")
+		Expect(p.renewCerts(data, time.Now().Add(p.CertValidity/2))).To(BeFalse())
+
+		By("renewing it once two thirds of its validity elapsed")
+		Expect(p.renewCerts(data, time.Now().Add(p.CertValidity*3/4))).To(BeTrue())
+		Expect(data[CACertKey]).To(Equal(ca))
+		Expect(data[CertKey]).NotTo(Equal(serving))
+		serving = data[CertKey]
+
+		By("renewing it when the DNS names change")
+		p.DNSNames = []string{"other.system.svc"}
+		Expect(p.renewCerts(data, time.Now())).To(BeTrue())
+		Expect(data[CACertKey]).To(Equal(ca))
+		Expect(data[CertKey]).NotTo(Equal(serving))
+	})
+
+	It("should renew the CA when it gets close to expiring, keeping the previous one in the bundle until it expires", func() {
+		data := map[string][]byte{}
+		Expect(p.renewCerts(data, time.Now())).To(BeTrue())
+		oldCA, oldServing := data[CACertKey], data[CertKey]
+
+		Expect(p.renewCerts(data, time.Now().Add(p.CAValidity*3/4))).To(BeTrue())
+		Expect(data[CACertKey]).NotTo(Equal(oldCA))
+		Expect(data[PreviousCACertKey]).To(Equal(oldCA))
+		Expect(data[CertKey]).NotTo(Equal(oldServing))
+
+		serving, err := parseKeyPair(data[CertKey], data[KeyKey])
+		Expect(err).NotTo(HaveOccurred())
+		ca, err := parseKeyPair(data[CACertKey], data[CAKeyKey])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(servesFor(serving, ca, p.DNSNames)).To(BeTrue())
+		Expect(caBundle(data)).To(Equal(append(append([]byte{}, data[CACertKey]...), oldCA...)))
+
+		By("dropping the previous CA once it expired")
+		Expect(p.renewCerts(data, time.Now().Add(p.CAValidity+time.Hour))).To(BeTrue())
+		Expect(data).NotTo(HaveKey(PreviousCACertKey))
+	})
+
+	It("should report webhooks it cannot inject the CA bundle into, but still write the certificates", func() {
+		p.CRDs = append(p.CRDs, "pods.example.com", "missing.example.com")
+
+		err := p.Provision(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(`the conversion strategy is "None"`))
+		Expect(err.Error()).To(ContainSubstring("missing.example.com"))
+		Expect(p.Ready()).To(BeClosed())
+		verify(getSecret().Data[CACertKey])
+	})
+
+	It("should keep serving the certificate of the previous CA until the new CA is trusted", func() {
+		Expect(p.Provision(ctx)).To(Succeed())
+		oldCA := getSecret().Data[CACertKey]
+
+		By("renewing the CA in the Secret")
+		secret := getSecret()
+		Expect(p.renewCerts(secret.Data, time.Now().Add(p.CAValidity*3/4))).To(BeTrue())
+		Expect(c.Update(ctx, secret)).To(Succeed())
+		Expect(secret.Data[CACertKey]).NotTo(Equal(oldCA))
+
+		By("failing to inject the new CA bundle")
+		crds := p.CRDs
+		p.CRDs = append(p.CRDs, "missing.example.com")
+		Expect(p.Provision(ctx)).NotTo(Succeed())
+		verify(oldCA)
+		written, err := ioutil.ReadFile(filepath.Join(dir, CertKey))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(written).NotTo(Equal(secret.Data[CertKey]))
+
+		By("serving the new certificate once the CA bundle is injected")
+		p.CRDs = crds
+		Expect(p.Provision(ctx)).To(Succeed())
+		written, err = ioutil.ReadFile(filepath.Join(dir, CertKey))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(written).To(Equal(secret.Data[CertKey]))
+		verify(secret.Data[CACertKey])
+	})
+
+	It("should provision the certificates once started, until stopped", func() {
+		stop := make(chan struct{})
+		done := make(chan error)
+		go func() {
+			done <- p.Start(stop)
+		}()
+
+		Eventually(p.Ready()).Should(BeClosed())
+		close(stop)
+		Eventually(done).Should(Receive(BeNil()))
+	})
+})
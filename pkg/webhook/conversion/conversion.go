@@ -22,17 +22,25 @@ See pkg/conversion for interface definitions required to ensure an API Type is c
 package conversion
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
 
 	apix "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/internal/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/internal/timeout"
 )
 
 var (
@@ -40,9 +48,64 @@ var (
 )
 
 // Webhook implements a CRD conversion webhook HTTP handler.
+//
+// Objects are converted between versions that have Go types in the scheme
+// through Hub and Convertible implementations.  Versions without Go types,
+// such as older versions of a CRD whose types are no longer kept around, can
+// be converted by conversion functions between unstructured objects,
+// registered with RegisterUnstructuredConversion.
 type Webhook struct {
 	scheme  *runtime.Scheme
 	decoder *Decoder
+
+	// mu guards unstructuredConversions
+	mu                      sync.RWMutex
+	unstructuredConversions map[conversionKey]UnstructuredConversionFunc
+}
+
+// UnstructuredConversionFunc converts the src object into dst.  dst starts out
+// as a copy of src with its apiVersion set to the version being converted to,
+// so the function only needs to change the fields that differ between the
+// two versions.
+type UnstructuredConversionFunc func(src, dst *unstructured.Unstructured) error
+
+// conversionKey identifies a conversion between two versions of a kind.
+type conversionKey struct {
+	from, to schema.GroupVersionKind
+}
+
+// RegisterUnstructuredConversion registers fn to convert objects of version
+// from to version to, which must be two different versions of the same kind.
+// Registered conversions take precedence over the conversions implemented by
+// the Go types of both versions.
+//
+// When either version has no Go type in the scheme, objects can also be
+// converted in two steps: between the version without a Go type and a version
+// with one by fn, and from there on by the Go types.  For example,
+// registering a conversion from v1alpha1 to the Hub version v1, and one back,
+// allows converting between v1alpha1 and all the Convertible versions.
+func (wh *Webhook) RegisterUnstructuredConversion(from, to schema.GroupVersionKind, fn UnstructuredConversionFunc) error {
+	if from.GroupKind() != to.GroupKind() {
+		return fmt.Errorf("cannot register a conversion between different kinds %s and %s", from.GroupKind(), to.GroupKind())
+	}
+	if from == to {
+		return fmt.Errorf("cannot register a conversion from %s to itself", from)
+	}
+	if fn == nil {
+		return fmt.Errorf("conversion function from %s to %s is nil", from, to)
+	}
+
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	key := conversionKey{from: from, to: to}
+	if _, exists := wh.unstructuredConversions[key]; exists {
+		return fmt.Errorf("a conversion from %s to %s is already registered", from, to)
+	}
+	if wh.unstructuredConversions == nil {
+		wh.unstructuredConversions = map[conversionKey]UnstructuredConversionFunc{}
+	}
+	wh.unstructuredConversions[key] = fn
+	return nil
 }
 
 // InjectScheme injects a scheme into the webhook, in order to construct a Decoder.
@@ -69,9 +132,17 @@ func (wh *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ctx, cancel, err := timeout.Context(r)
+	if err != nil {
+		log.Error(err, "unable to process a conversion request with an invalid timeout")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer cancel()
+
 	// TODO(droot): may be move the conversion logic to a separate module to
 	// decouple it from the http layer ?
-	resp, err := wh.handleConvertRequest(convertReview.Request)
+	resp, err := wh.handleConvertRequest(ctx, convertReview.Request)
 	if err != nil {
 		log.Error(err, "failed to convert", "request", convertReview.Request.UID)
 		convertReview.Response = errored(err)
@@ -88,28 +159,55 @@ func (wh *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handles a version conversion request.
-func (wh *Webhook) handleConvertRequest(req *apix.ConversionRequest) (*apix.ConversionResponse, error) {
+// handles a version conversion request.  It stops converting objects once the
+// given context is done, and turns panics into a failed response.
+func (wh *Webhook) handleConvertRequest(ctx context.Context, req *apix.ConversionRequest) (resp *apix.ConversionResponse, err error) {
 	if req == nil {
 		return nil, fmt.Errorf("conversion request is nil")
 	}
-	var objects []runtime.RawExtension
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr := fmt.Errorf("panic: %v [recovered]", r)
+			log.Error(panicErr, "observed a panic while converting", "request", req.UID, "stacktrace", string(debug.Stack()))
+			metrics.WebhookPanics.WithLabelValues("conversion").Inc()
 
-	for _, obj := range req.Objects {
-		src, gvk, err := wh.decoder.Decode(obj.Raw)
-		if err != nil {
-			return nil, err
+			resp = errored(panicErr)
+			resp.Result.Code = http.StatusInternalServerError
+			resp.Result.Reason = metav1.StatusReasonInternalError
+		}
+	}()
+	var objects []runtime.RawExtension
+	var failures []string
+	for i, obj := range req.Objects {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("conversion did not complete in time: %w", err)
+		}
+		src := &unstructured.Unstructured{}
+		if err := src.UnmarshalJSON(obj.Raw); err != nil {
+			failures = append(failures, fmt.Sprintf("object %d: %v", i, err))
+			continue
 		}
-		dst, err := wh.allocateDstObject(req.DesiredAPIVersion, gvk.Kind)
+		srcGVK := src.GroupVersionKind()
+		dstGVK := schema.FromAPIVersionAndKind(req.DesiredAPIVersion, srcGVK.Kind)
+
+		dst, err := wh.convert(obj.Raw, src, dstGVK)
+		result := "success"
 		if err != nil {
-			return nil, err
+			result = "error"
+			failures = append(failures, fmt.Sprintf("%s %s: %v", srcGVK.Kind, objectName(i, src), err))
 		}
-		err = wh.convertObject(src, dst)
+		metrics.ConversionTotal.WithLabelValues(srcGVK.Kind, srcGVK.GroupVersion().String(), dstGVK.GroupVersion().String(), result).Inc()
 		if err != nil {
-			return nil, err
+			continue
 		}
 		objects = append(objects, runtime.RawExtension{Object: dst})
 	}
+	if len(failures) > 0 {
+		// the API server needs either all or none of the objects converted,
+		// so report every failure at once
+		return nil, fmt.Errorf("failed to convert %d of %d objects to %s: %s",
+			len(failures), len(req.Objects), req.DesiredAPIVersion, strings.Join(failures, "; "))
+	}
 	return &apix.ConversionResponse{
 		UID:              req.UID,
 		ConvertedObjects: objects,
@@ -119,6 +217,156 @@ func (wh *Webhook) handleConvertRequest(req *apix.ConversionRequest) (*apix.Conv
 	}, nil
 }
 
+// objectName returns the namespace/name of obj for error messages, or its
+// index in the request if it has no name.
+func objectName(i int, obj *unstructured.Unstructured) string {
+	if obj.GetName() == "" {
+		return fmt.Sprintf("at index %d", i)
+	}
+	if obj.GetNamespace() == "" {
+		return fmt.Sprintf("%q", obj.GetName())
+	}
+	return fmt.Sprintf("%q", obj.GetNamespace()+"/"+obj.GetName())
+}
+
+// convert converts src, whose serialized form is raw, to the given version.
+func (wh *Webhook) convert(raw []byte, src *unstructured.Unstructured, dstGVK schema.GroupVersionKind) (runtime.Object, error) {
+	srcGVK := src.GroupVersionKind()
+	if fn := wh.unstructuredConversion(srcGVK, dstGVK); fn != nil {
+		return convertUnstructured(fn, src, dstGVK)
+	}
+
+	srcIsTyped, dstIsTyped := wh.scheme.Recognizes(srcGVK), wh.scheme.Recognizes(dstGVK)
+	switch {
+	case srcIsTyped && dstIsTyped:
+		typedSrc, _, err := wh.decoder.Decode(raw)
+		if err != nil {
+			return nil, err
+		}
+		return wh.convertTyped(typedSrc, dstGVK)
+
+	case !srcIsTyped && dstIsTyped:
+		// convert to a version with a Go type, and from there on to the desired version
+		viaGVK, fn := wh.unstructuredConversionVia(srcGVK, true)
+		if fn == nil {
+			break
+		}
+		via, err := convertUnstructured(fn, src, viaGVK)
+		if err != nil {
+			return nil, err
+		}
+		typedVia, err := wh.scheme.New(viaGVK)
+		if err != nil {
+			return nil, err
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(via.Object, typedVia); err != nil {
+			return nil, err
+		}
+		typedVia.GetObjectKind().SetGroupVersionKind(viaGVK)
+		if viaGVK == dstGVK {
+			return typedVia, nil
+		}
+		return wh.convertTyped(typedVia, dstGVK)
+
+	case srcIsTyped && !dstIsTyped:
+		// convert to a version with a Go type, and from there on to the desired version
+		viaGVK, fn := wh.unstructuredConversionVia(dstGVK, false)
+		if fn == nil {
+			break
+		}
+		typedSrc, _, err := wh.decoder.Decode(raw)
+		if err != nil {
+			return nil, err
+		}
+		typedVia := typedSrc
+		if viaGVK != srcGVK {
+			if typedVia, err = wh.convertTyped(typedSrc, viaGVK); err != nil {
+				return nil, err
+			}
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(typedVia)
+		if err != nil {
+			return nil, err
+		}
+		via := &unstructured.Unstructured{Object: content}
+		via.SetGroupVersionKind(viaGVK)
+		return convertUnstructured(fn, via, dstGVK)
+	}
+
+	return nil, fmt.Errorf("no conversion from %s to %s is known", srcGVK.GroupVersion(), dstGVK.GroupVersion())
+}
+
+// convertTyped converts src to a newly allocated object of the given version.
+func (wh *Webhook) convertTyped(src runtime.Object, dstGVK schema.GroupVersionKind) (runtime.Object, error) {
+	dst, err := wh.allocateDstObject(dstGVK.GroupVersion().String(), dstGVK.Kind)
+	if err != nil {
+		return nil, err
+	}
+	if err := wh.convertObject(src, dst); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// unstructuredConversion returns the conversion registered from one version
+// to the other, if any.
+func (wh *Webhook) unstructuredConversion(from, to schema.GroupVersionKind) UnstructuredConversionFunc {
+	wh.mu.RLock()
+	defer wh.mu.RUnlock()
+	return wh.unstructuredConversions[conversionKey{from: from, to: to}]
+}
+
+// unstructuredConversionVia looks for a conversion registered between the
+// given version and a version with a Go type in the scheme, from the given
+// version if fromGVK is true and to it otherwise.  Conversions involving the
+// Hub of the kind are preferred, and other candidates are picked in a
+// deterministic order.
+func (wh *Webhook) unstructuredConversionVia(gvk schema.GroupVersionKind, fromGVK bool) (schema.GroupVersionKind, UnstructuredConversionFunc) {
+	wh.mu.RLock()
+	defer wh.mu.RUnlock()
+
+	var candidates []schema.GroupVersionKind
+	for key := range wh.unstructuredConversions {
+		end, other := key.to, key.from
+		if !fromGVK {
+			end, other = key.from, key.to
+		}
+		if other == gvk && wh.scheme.Recognizes(end) {
+			candidates = append(candidates, end)
+		}
+	}
+	if len(candidates) == 0 {
+		return schema.GroupVersionKind{}, nil
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Version < candidates[j].Version
+	})
+
+	via := candidates[0]
+	for _, candidate := range candidates {
+		if instance, err := wh.scheme.New(candidate); err == nil && isHub(instance) {
+			via = candidate
+			break
+		}
+	}
+	if fromGVK {
+		return via, wh.unstructuredConversions[conversionKey{from: gvk, to: via}]
+	}
+	return via, wh.unstructuredConversions[conversionKey{from: via, to: gvk}]
+}
+
+// convertUnstructured converts src to the given version with fn.
+func convertUnstructured(fn UnstructuredConversionFunc, src *unstructured.Unstructured, dstGVK schema.GroupVersionKind) (*unstructured.Unstructured, error) {
+	dst := src.DeepCopy()
+	dst.SetGroupVersionKind(dstGVK)
+	if err := fn(src.DeepCopy(), dst); err != nil {
+		return nil, err
+	}
+	// make sure the function did not change the version behind our back
+	dst.SetGroupVersionKind(dstGVK)
+	return dst, nil
+}
+
 // convertObject will convert given a src object to dst object.
 // Note(droot): couldn't find a way to reduce the cyclomatic complexity under 10
 // without compromising readability, so disabling gocyclo linter
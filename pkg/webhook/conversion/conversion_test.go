@@ -19,13 +19,18 @@ package conversion
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	appsv1beta1 "k8s.io/api/apps/v1beta1"
 	apix "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,9 +38,11 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	kscheme "k8s.io/client-go/kubernetes/scheme"
 
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
 	jobsv1 "sigs.k8s.io/controller-runtime/pkg/webhook/conversion/testdata/api/v1"
 	jobsv2 "sigs.k8s.io/controller-runtime/pkg/webhook/conversion/testdata/api/v2"
 	jobsv3 "sigs.k8s.io/controller-runtime/pkg/webhook/conversion/testdata/api/v3"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/internal/metrics"
 )
 
 var _ = Describe("Conversion Webhook", func() {
@@ -300,6 +307,247 @@ var _ = Describe("Conversion Webhook", func() {
 
 })
 
+var _ = Describe("Conversion Webhook with misbehaving conversions", func() {
+	var webhook *Webhook
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		scheme.AddKnownTypeWithName(jobsv1.GroupVersion.WithKind("ExternalJob"), &misbehavingJob{})
+		Expect(jobsv2.AddToScheme(scheme)).To(Succeed())
+
+		webhook = &Webhook{}
+		Expect(webhook.InjectScheme(scheme)).To(Succeed())
+	})
+
+	doRequest := func(query string, runAts ...string) *apix.ConversionReview {
+		convReq := &apix.ConversionReview{
+			Request: &apix.ConversionRequest{
+				UID:               "123",
+				DesiredAPIVersion: "jobs.testprojects.kb.io/v2",
+			},
+		}
+		for i, runAt := range runAts {
+			convReq.Request.Objects = append(convReq.Request.Objects, runtime.RawExtension{Object: &misbehavingJob{
+				ExternalJob: jobsv1.ExternalJob{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ExternalJob",
+						APIVersion: "jobs.testprojects.kb.io/v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "default",
+						Name:      fmt.Sprintf("obj-%d", i),
+					},
+					Spec: jobsv1.ExternalJobSpec{RunAt: runAt},
+				},
+			}})
+		}
+
+		var payload bytes.Buffer
+		Expect(json.NewEncoder(&payload).Encode(convReq)).Should(Succeed())
+		req := &http.Request{
+			URL:  &url.URL{RawQuery: query},
+			Body: ioutil.NopCloser(bytes.NewReader(payload.Bytes())),
+		}
+		respRecorder := httptest.NewRecorder()
+		webhook.ServeHTTP(respRecorder, req)
+
+		convReview := &apix.ConversionReview{}
+		Expect(json.NewDecoder(respRecorder.Result().Body).Decode(convReview)).To(Succeed())
+		return convReview
+	}
+
+	It("should recover from a panicking conversion with a failed response", func() {
+		panics := testutil.ToFloat64(metrics.WebhookPanics.WithLabelValues("conversion"))
+
+		convReview := doRequest("", "panic")
+
+		Expect(convReview.Response.UID).To(BeEquivalentTo("123"))
+		Expect(convReview.Response.Result.Status).To(Equal(metav1.StatusFailure))
+		Expect(convReview.Response.Result.Code).To(BeEquivalentTo(http.StatusInternalServerError))
+		Expect(convReview.Response.Result.Message).To(ContainSubstring("boom"))
+		Expect(convReview.Response.ConvertedObjects).To(BeEmpty())
+		Expect(testutil.ToFloat64(metrics.WebhookPanics.WithLabelValues("conversion"))).To(Equal(panics + 1))
+	})
+
+	It("should stop converting once the requested timeout elapses", func() {
+		convReview := doRequest("timeout=10ms", "slowly", "slowly")
+
+		Expect(convReview.Response.Result.Status).To(Equal(metav1.StatusFailure))
+		Expect(convReview.Response.Result.Message).To(ContainSubstring("did not complete in time"))
+		Expect(convReview.Response.ConvertedObjects).To(BeEmpty())
+	})
+
+	It("should convert objects when the requested timeout is long enough", func() {
+		convReview := doRequest("timeout=10s", "every 2 seconds")
+
+		Expect(convReview.Response.Result.Status).To(Equal(metav1.StatusSuccess))
+		Expect(convReview.Response.ConvertedObjects).To(HaveLen(1))
+	})
+
+	It("should report every object that failed to convert by name", func() {
+		convReview := doRequest("", "fail", "every 2 seconds", "fail")
+
+		Expect(convReview.Response.UID).To(BeEquivalentTo("123"))
+		Expect(convReview.Response.Result.Status).To(Equal(metav1.StatusFailure))
+		Expect(convReview.Response.Result.Message).To(HavePrefix("failed to convert 2 of 3 objects to jobs.testprojects.kb.io/v2"))
+		Expect(convReview.Response.Result.Message).To(ContainSubstring(`ExternalJob "default/obj-0": cannot run at fail`))
+		Expect(convReview.Response.Result.Message).NotTo(ContainSubstring(`"default/obj-1"`))
+		Expect(convReview.Response.Result.Message).To(ContainSubstring(`ExternalJob "default/obj-2": cannot run at fail`))
+		Expect(convReview.Response.ConvertedObjects).To(BeEmpty())
+	})
+
+	It("should count the conversions by version and result", func() {
+		counter := func(result string) float64 {
+			return testutil.ToFloat64(metrics.ConversionTotal.WithLabelValues(
+				"ExternalJob", "jobs.testprojects.kb.io/v1", "jobs.testprojects.kb.io/v2", result))
+		}
+		successes, failures := counter("success"), counter("error")
+
+		doRequest("", "fail", "every 2 seconds", "every 3 seconds")
+
+		Expect(counter("success")).To(Equal(successes + 2))
+		Expect(counter("error")).To(Equal(failures + 1))
+	})
+})
+
+var _ = Describe("Conversion Webhook with unstructured conversions", func() {
+	var webhook *Webhook
+
+	v1GVK := jobsv1.GroupVersion.WithKind("ExternalJob")
+	v2GVK := jobsv2.GroupVersion.WithKind("ExternalJob")
+	v3GVK := jobsv3.GroupVersion.WithKind("ExternalJob")
+
+	// renameSpecField returns a conversion that moves a field of the spec
+	renameSpecField := func(from, to string) UnstructuredConversionFunc {
+		return func(src, dst *unstructured.Unstructured) error {
+			value, _, err := unstructured.NestedString(src.Object, "spec", from)
+			if err != nil {
+				return err
+			}
+			unstructured.RemoveNestedField(dst.Object, "spec", from)
+			return unstructured.SetNestedField(dst.Object, value, "spec", to)
+		}
+	}
+
+	BeforeEach(func() {
+		By("leaving v1 out of the scheme")
+		scheme := runtime.NewScheme()
+		Expect(jobsv2.AddToScheme(scheme)).To(Succeed())
+		Expect(jobsv3.AddToScheme(scheme)).To(Succeed())
+
+		webhook = &Webhook{}
+		Expect(webhook.InjectScheme(scheme)).To(Succeed())
+
+		By("registering conversions between v1 and the hub")
+		Expect(webhook.RegisterUnstructuredConversion(v1GVK, v2GVK, renameSpecField("runAt", "scheduleAt"))).To(Succeed())
+		Expect(webhook.RegisterUnstructuredConversion(v2GVK, v1GVK, renameSpecField("scheduleAt", "runAt"))).To(Succeed())
+	})
+
+	convert := func(desiredAPIVersion string, obj runtime.Object) *apix.ConversionReview {
+		convReq := &apix.ConversionReview{
+			Request: &apix.ConversionRequest{
+				UID:               "123",
+				DesiredAPIVersion: desiredAPIVersion,
+				Objects:           []runtime.RawExtension{{Object: obj}},
+			},
+		}
+
+		var payload bytes.Buffer
+		Expect(json.NewEncoder(&payload).Encode(convReq)).Should(Succeed())
+		req := &http.Request{
+			Body: ioutil.NopCloser(bytes.NewReader(payload.Bytes())),
+		}
+		respRecorder := httptest.NewRecorder()
+		webhook.ServeHTTP(respRecorder, req)
+
+		convReview := &apix.ConversionReview{}
+		Expect(json.NewDecoder(respRecorder.Result().Body).Decode(convReview)).To(Succeed())
+		return convReview
+	}
+
+	convertedObject := func(convReview *apix.ConversionReview) map[string]interface{} {
+		Expect(convReview.Response.Result.Message).To(BeEmpty())
+		Expect(convReview.Response.Result.Status).To(Equal(metav1.StatusSuccess))
+		Expect(convReview.Response.ConvertedObjects).To(HaveLen(1))
+		obj := map[string]interface{}{}
+		Expect(json.Unmarshal(convReview.Response.ConvertedObjects[0].Raw, &obj)).To(Succeed())
+		return obj
+	}
+
+	v1Obj := func() *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(v1GVK)
+		obj.SetNamespace("default")
+		obj.SetName("obj-1")
+		Expect(unstructured.SetNestedField(obj.Object, "every 2 seconds", "spec", "runAt")).To(Succeed())
+		return obj
+	}
+
+	It("should convert with a conversion registered between both versions", func() {
+		obj := convertedObject(convert("jobs.testprojects.kb.io/v2", v1Obj()))
+		Expect(obj).To(HaveKeyWithValue("apiVersion", "jobs.testprojects.kb.io/v2"))
+		Expect(obj).To(HaveKeyWithValue("spec", HaveKeyWithValue("scheduleAt", "every 2 seconds")))
+		Expect(obj).To(HaveKeyWithValue("spec", Not(HaveKey("runAt"))))
+		Expect(obj).To(HaveKeyWithValue("metadata", HaveKeyWithValue("name", "obj-1")))
+	})
+
+	It("should convert from a version without a Go type through the Go types", func() {
+		obj := convertedObject(convert("jobs.testprojects.kb.io/v3", v1Obj()))
+		Expect(obj).To(HaveKeyWithValue("apiVersion", "jobs.testprojects.kb.io/v3"))
+		Expect(obj).To(HaveKeyWithValue("spec", HaveKeyWithValue("deferredAt", "every 2 seconds")))
+		Expect(obj).To(HaveKeyWithValue("metadata", HaveKeyWithValue("name", "obj-1")))
+	})
+
+	It("should convert to a version without a Go type through the Go types", func() {
+		obj := convertedObject(convert("jobs.testprojects.kb.io/v1", &jobsv3.ExternalJob{
+			TypeMeta:   metav1.TypeMeta{Kind: "ExternalJob", APIVersion: "jobs.testprojects.kb.io/v3"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "obj-1"},
+			Spec:       jobsv3.ExternalJobSpec{DeferredAt: "every 2 seconds"},
+		}))
+		Expect(obj).To(HaveKeyWithValue("apiVersion", "jobs.testprojects.kb.io/v1"))
+		Expect(obj).To(HaveKeyWithValue("spec", HaveKeyWithValue("runAt", "every 2 seconds")))
+		Expect(obj).To(HaveKeyWithValue("spec", Not(HaveKey("scheduleAt"))))
+		Expect(obj).To(HaveKeyWithValue("metadata", HaveKeyWithValue("name", "obj-1")))
+	})
+
+	It("should fail to convert between versions without any known conversion", func() {
+		convReview := convert("jobs.testprojects.kb.io/v0", v1Obj())
+		Expect(convReview.Response.Result.Status).To(Equal(metav1.StatusFailure))
+		Expect(convReview.Response.Result.Message).To(ContainSubstring(
+			"no conversion from jobs.testprojects.kb.io/v1 to jobs.testprojects.kb.io/v0 is known"))
+	})
+
+	It("should refuse to register invalid or duplicate conversions", func() {
+		fn := renameSpecField("runAt", "scheduleAt")
+		Expect(webhook.RegisterUnstructuredConversion(v1GVK, v2GVK, fn)).NotTo(Succeed())
+		Expect(webhook.RegisterUnstructuredConversion(v1GVK, v1GVK, fn)).NotTo(Succeed())
+		Expect(webhook.RegisterUnstructuredConversion(v1GVK, v2GVK.GroupVersion().WithKind("OtherJob"), fn)).NotTo(Succeed())
+		Expect(webhook.RegisterUnstructuredConversion(v1GVK, v3GVK, nil)).NotTo(Succeed())
+	})
+})
+
+// misbehavingJob is a v1 ExternalJob whose conversion to the hub fails,
+// panics or is slow, depending on when it should run.
+type misbehavingJob struct {
+	jobsv1.ExternalJob
+}
+
+func (j *misbehavingJob) ConvertTo(dst conversion.Hub) error {
+	switch j.Spec.RunAt {
+	case "fail":
+		return errors.New("cannot run at fail")
+	case "panic":
+		panic("boom")
+	case "slowly":
+		time.Sleep(50 * time.Millisecond)
+	}
+	return j.ExternalJob.ConvertTo(dst)
+}
+
+func (j *misbehavingJob) DeepCopyObject() runtime.Object {
+	return &misbehavingJob{ExternalJob: *j.ExternalJob.DeepCopy()}
+}
+
 var _ = Describe("IsConvertible", func() {
 
 	var scheme *runtime.Scheme
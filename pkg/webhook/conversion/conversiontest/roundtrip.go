@@ -0,0 +1,225 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conversiontest contains helpers to test the conversions between the
+// versions of a kind implemented with conversion.Hub and conversion.Convertible.
+package conversiontest
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/diff"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+	webhookconversion "sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+)
+
+// RoundTripOptions configures RoundTrip.
+type RoundTripOptions struct {
+	// Iterations is the number of objects fuzzed for each version and
+	// direction of conversion.  Defaults to 100.
+	Iterations int
+
+	// Seed is the seed of the fuzzer.  If zero, a seed is picked from the
+	// current time, and reported along with any failure, so that failures can
+	// be reproduced.
+	Seed int64
+
+	// FuzzerFuncs are custom fuzzing functions, of the form
+	// func(*SomeType, fuzz.Continue), for types that need to be fuzzed in a
+	// particular way, for instance because only some of their values are
+	// valid.  They take precedence over the functions for the types of the
+	// ObjectMeta package.
+	FuzzerFuncs []interface{}
+}
+
+// RoundTrip checks that the conversions between the versions of the given
+// group-kind in the scheme are lossless.  The kind must be convertible, as
+// determined by conversion.IsConvertible.  For every spoke version, fuzzed
+// spoke objects are converted to the hub and back, and fuzzed hub objects are
+// converted to the spoke and back; the objects must be unchanged.
+//
+// The returned error lists the differences found, field by field, for the
+// first object that did not survive each round trip.
+func RoundTrip(scheme *runtime.Scheme, gk schema.GroupKind, opts RoundTripOptions) error {
+	if opts.Iterations <= 0 {
+		opts.Iterations = 100
+	}
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+
+	hubGVK, spokeGVKs, err := versionsOf(scheme, gk)
+	if err != nil {
+		return err
+	}
+
+	codecs := runtimeserializer.NewCodecFactory(scheme)
+	funcs := fuzzer.MergeFuzzerFuncs(metafuzzer.Funcs, func(runtimeserializer.CodecFactory) []interface{} {
+		return opts.FuzzerFuncs
+	})
+	f := fuzzer.FuzzerFor(funcs, rand.NewSource(opts.Seed), codecs)
+
+	var errs []error
+	for _, spokeGVK := range spokeGVKs {
+		for i := 0; i < opts.Iterations; i++ {
+			if err := spokeRoundTrip(scheme, f.Fuzz, spokeGVK, hubGVK); err != nil {
+				errs = append(errs, fmt.Errorf("%s -> %s -> %s (seed %d, iteration %d): %w",
+					spokeGVK.Version, hubGVK.Version, spokeGVK.Version, opts.Seed, i, err))
+				break
+			}
+		}
+		for i := 0; i < opts.Iterations; i++ {
+			if err := hubRoundTrip(scheme, f.Fuzz, hubGVK, spokeGVK); err != nil {
+				errs = append(errs, fmt.Errorf("%s -> %s -> %s (seed %d, iteration %d): %w",
+					hubGVK.Version, spokeGVK.Version, hubGVK.Version, opts.Seed, i, err))
+				break
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// versionsOf returns the hub and the spoke versions of the given group-kind.
+func versionsOf(scheme *runtime.Scheme, gk schema.GroupKind) (schema.GroupVersionKind, []schema.GroupVersionKind, error) {
+	var hubGVK schema.GroupVersionKind
+	var spokeGVKs []schema.GroupVersionKind
+	for gvk := range scheme.AllKnownTypes() {
+		if gvk.GroupKind() != gk {
+			continue
+		}
+		obj, err := scheme.New(gvk)
+		if err != nil {
+			return hubGVK, nil, err
+		}
+		if _, isHub := obj.(conversion.Hub); isHub {
+			hubGVK = gvk
+		} else {
+			spokeGVKs = append(spokeGVKs, gvk)
+		}
+	}
+	if len(spokeGVKs) == 0 && hubGVK.Empty() {
+		return hubGVK, nil, fmt.Errorf("%s is not registered in the scheme", gk)
+	}
+
+	obj, err := scheme.New(gk.WithVersion(firstVersion(hubGVK, spokeGVKs)))
+	if err != nil {
+		return hubGVK, nil, err
+	}
+	convertible, err := webhookconversion.IsConvertible(scheme, obj)
+	if err != nil {
+		return hubGVK, nil, err
+	}
+	if !convertible {
+		return hubGVK, nil, fmt.Errorf("%s is not convertible", gk)
+	}
+
+	sort.Slice(spokeGVKs, func(i, j int) bool {
+		return spokeGVKs[i].Version < spokeGVKs[j].Version
+	})
+	return hubGVK, spokeGVKs, nil
+}
+
+func firstVersion(hubGVK schema.GroupVersionKind, spokeGVKs []schema.GroupVersionKind) string {
+	if !hubGVK.Empty() {
+		return hubGVK.Version
+	}
+	return spokeGVKs[0].Version
+}
+
+// spokeRoundTrip converts a fuzzed spoke object to the hub and back.
+func spokeRoundTrip(scheme *runtime.Scheme, fuzz func(interface{}), spokeGVK, hubGVK schema.GroupVersionKind) (err error) {
+	defer recoverConversion(&err)
+
+	original, hub, result, err := newObjects(scheme, spokeGVK, hubGVK)
+	if err != nil {
+		return err
+	}
+	fuzz(original)
+
+	spoke := original.DeepCopyObject()
+	if err := spoke.(conversion.Convertible).ConvertTo(hub.(conversion.Hub)); err != nil {
+		return fmt.Errorf("unable to convert to the hub: %w", err)
+	}
+	if err := result.(conversion.Convertible).ConvertFrom(hub.(conversion.Hub)); err != nil {
+		return fmt.Errorf("unable to convert from the hub: %w", err)
+	}
+	return compare(original, result)
+}
+
+// hubRoundTrip converts a fuzzed hub object to a spoke and back.
+func hubRoundTrip(scheme *runtime.Scheme, fuzz func(interface{}), hubGVK, spokeGVK schema.GroupVersionKind) (err error) {
+	defer recoverConversion(&err)
+
+	original, spoke, result, err := newObjects(scheme, hubGVK, spokeGVK)
+	if err != nil {
+		return err
+	}
+	fuzz(original)
+
+	hub := original.DeepCopyObject()
+	if err := spoke.(conversion.Convertible).ConvertFrom(hub.(conversion.Hub)); err != nil {
+		return fmt.Errorf("unable to convert from the hub: %w", err)
+	}
+	if err := spoke.(conversion.Convertible).ConvertTo(result.(conversion.Hub)); err != nil {
+		return fmt.Errorf("unable to convert to the hub: %w", err)
+	}
+	return compare(original, result)
+}
+
+// newObjects allocates the original object of a round trip, the object it is
+// converted to, and the result of the conversion back.
+func newObjects(scheme *runtime.Scheme, gvk, viaGVK schema.GroupVersionKind) (original, via, result runtime.Object, err error) {
+	if original, err = scheme.New(gvk); err != nil {
+		return nil, nil, nil, err
+	}
+	if via, err = scheme.New(viaGVK); err != nil {
+		return nil, nil, nil, err
+	}
+	if result, err = scheme.New(gvk); err != nil {
+		return nil, nil, nil, err
+	}
+	return original, via, result, nil
+}
+
+// compare returns an error describing the differences between the original
+// object and the result of its round trip, if any.  Type information is not
+// compared, since conversions are free to set it or not.
+func compare(original, result runtime.Object) error {
+	original.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{})
+	result.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{})
+	if equality.Semantic.DeepEqual(original, result) {
+		return nil
+	}
+	return fmt.Errorf("the object changed (-original +result):\n%s", diff.ObjectReflectDiff(original, result))
+}
+
+// recoverConversion turns a panicking conversion into an error.
+func recoverConversion(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("conversion panicked: %v", r)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversiontest_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion/conversiontest"
+	jobsv1 "sigs.k8s.io/controller-runtime/pkg/webhook/conversion/testdata/api/v1"
+	jobsv2 "sigs.k8s.io/controller-runtime/pkg/webhook/conversion/testdata/api/v2"
+	jobsv3 "sigs.k8s.io/controller-runtime/pkg/webhook/conversion/testdata/api/v3"
+)
+
+var _ = Describe("RoundTrip", func() {
+	externalJob := schema.GroupKind{Group: jobsv1.GroupVersion.Group, Kind: "ExternalJob"}
+	var scheme *runtime.Scheme
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(jobsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(jobsv2.AddToScheme(scheme)).To(Succeed())
+	})
+
+	It("should succeed for lossless conversions", func() {
+		Expect(jobsv3.AddToScheme(scheme)).To(Succeed())
+		Expect(conversiontest.RoundTrip(scheme, externalJob, conversiontest.RoundTripOptions{})).To(Succeed())
+	})
+
+	It("should report the fields lost by conversions in both directions", func() {
+		scheme.AddKnownTypeWithName(jobsv3.GroupVersion.WithKind("ExternalJob"), &lossyJob{})
+		scheme.AddKnownTypeWithName(jobsv3.GroupVersion.WithKind("ExternalJobList"), &jobsv3.ExternalJobList{})
+
+		err := conversiontest.RoundTrip(scheme, externalJob, conversiontest.RoundTripOptions{Seed: 42})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("v3 -> v2 -> v3 (seed 42"))
+		Expect(err.Error()).To(ContainSubstring("v2 -> v3 -> v2 (seed 42"))
+		Expect(err.Error()).To(ContainSubstring("DeferredAt"))
+		Expect(err.Error()).To(ContainSubstring("ScheduleAt"))
+		Expect(err.Error()).NotTo(ContainSubstring("v1 ->"))
+	})
+
+	It("should report panicking conversions", func() {
+		scheme.AddKnownTypeWithName(jobsv3.GroupVersion.WithKind("ExternalJob"), &panickingJob{})
+		scheme.AddKnownTypeWithName(jobsv3.GroupVersion.WithKind("ExternalJobList"), &jobsv3.ExternalJobList{})
+
+		err := conversiontest.RoundTrip(scheme, externalJob, conversiontest.RoundTripOptions{Iterations: 1})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("conversion panicked: boom"))
+	})
+
+	It("should refuse kinds that are not convertible", func() {
+		scheme.AddKnownTypeWithName(jobsv3.GroupVersion.WithKind("ExternalJob"), &jobsv3.ExternalJobList{})
+
+		Expect(conversiontest.RoundTrip(scheme, externalJob, conversiontest.RoundTripOptions{})).NotTo(Succeed())
+	})
+
+	It("should refuse kinds that are not in the scheme", func() {
+		err := conversiontest.RoundTrip(scheme, schema.GroupKind{Group: "example.com", Kind: "Unknown"}, conversiontest.RoundTripOptions{})
+		Expect(err).To(MatchError(ContainSubstring("not registered in the scheme")))
+	})
+})
+
+// lossyJob is a v3 ExternalJob which loses its schedule when converted from
+// the hub.
+type lossyJob struct {
+	jobsv3.ExternalJob
+}
+
+func (j *lossyJob) ConvertFrom(src conversion.Hub) error {
+	if err := j.ExternalJob.ConvertFrom(src); err != nil {
+		return err
+	}
+	j.Spec.DeferredAt = ""
+	return nil
+}
+
+func (j *lossyJob) DeepCopyObject() runtime.Object {
+	return &lossyJob{ExternalJob: *j.ExternalJob.DeepCopy()}
+}
+
+// panickingJob is a v3 ExternalJob whose conversions panic.
+type panickingJob struct {
+	jobsv3.ExternalJob
+}
+
+func (j *panickingJob) ConvertTo(dst conversion.Hub) error {
+	panic("boom")
+}
+
+func (j *panickingJob) ConvertFrom(src conversion.Hub) error {
+	panic("boom")
+}
+
+func (j *panickingJob) DeepCopyObject() runtime.Object {
+	return &panickingJob{ExternalJob: *j.ExternalJob.DeepCopy()}
+}
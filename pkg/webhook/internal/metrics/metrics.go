@@ -54,8 +54,34 @@ var (
 			[]string{"webhook"},
 		)
 	}()
+
+	// WebhookPanics is a prometheus metric which is a counter of the panics
+	// recovered from while handling webhook requests, by type of webhook
+	// ("admission" or "conversion").
+	WebhookPanics = func() *prometheus.CounterVec {
+		return prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "controller_runtime_webhook_panics_total",
+				Help: "Total number of panics recovered from while handling webhook requests.",
+			},
+			[]string{"webhook_type"},
+		)
+	}()
+
+	// ConversionTotal is a prometheus metric which is a counter of the objects
+	// converted by conversion webhooks, by kind, source and target version, and
+	// result ("success" or "error").
+	ConversionTotal = func() *prometheus.CounterVec {
+		return prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "controller_runtime_webhook_conversions_total",
+				Help: "Total number of objects converted by conversion webhooks by kind, source and target version, and result.",
+			},
+			[]string{"kind", "from_version", "to_version", "result"},
+		)
+	}()
 )
 
 func init() {
-	metrics.Registry.MustRegister(RequestLatency, RequestTotal, RequestInFlight)
+	metrics.Registry.MustRegister(RequestLatency, RequestTotal, RequestInFlight, WebhookPanics, ConversionTotal)
 }
@@ -0,0 +1,46 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package timeout helps webhooks honor the timeout that the API server sets
+// on the requests it sends them.
+package timeout
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Context returns a context for handling the given webhook request, which is
+// cancelled once the timeout set by the API server through the "timeout"
+// query parameter elapses.  If the request has no timeout, the context of the
+// request is returned as-is.
+func Context(r *http.Request) (context.Context, context.CancelFunc, error) {
+	var raw string
+	if r.URL != nil {
+		raw = r.URL.Query().Get("timeout")
+	}
+	if raw == "" {
+		return r.Context(), func() {}, nil
+	}
+	timeout, err := time.ParseDuration(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid timeout %q: %w", raw, err)
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	return ctx, cancel, nil
+}
@@ -19,20 +19,20 @@ package webhook
 import (
 	"context"
 	"crypto/tls"
-	"crypto/x509"
 	"fmt"
-	"io/ioutil"
 	"net"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/internal/certwatcher"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
-	"sigs.k8s.io/controller-runtime/pkg/webhook/internal/certwatcher"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/internal/metrics"
 )
 
@@ -62,8 +62,20 @@ type Server struct {
 
 	// ClientCAName is the CA certificate name which server used to verify remote(client)'s certificate.
 	// Defaults to "", which means server does not verify client's certificate.
+	// Like the server certificate, it is reloaded whenever it changes.
 	ClientCAName string
 
+	// TLSOpts is used to allow configuring the TLS config used for the server,
+	// for instance to set the minimum TLS version or the allowed cipher suites.
+	// The functions are applied in order, once the certificates have been
+	// configured.
+	TLSOpts []func(*tls.Config)
+
+	// CertsReady, if set, delays loading the certificate and serving until it
+	// is closed, so that the certificate can be provisioned once the manager
+	// has started, for instance by a certs.Provisioner.
+	CertsReady <-chan struct{}
+
 	// WebhookMux is the multiplexer that handles different webhooks.
 	WebhookMux *http.ServeMux
 
@@ -76,6 +88,12 @@ type Server struct {
 
 	// defaultingOnce ensures that the default fields are only ever set once.
 	defaultingOnce sync.Once
+
+	// mu protects access to the started field.
+	mu sync.Mutex
+
+	// started is set to true once the server listens for connections.
+	started bool
 }
 
 // setDefaults does defaulting for the Server.
@@ -166,40 +184,24 @@ func (s *Server) Start(stop <-chan struct{}) error {
 		}
 	}
 
-	certPath := filepath.Join(s.CertDir, s.CertName)
-	keyPath := filepath.Join(s.CertDir, s.KeyName)
-
-	certWatcher, err := certwatcher.New(certPath, keyPath)
-	if err != nil {
-		return err
-	}
-
-	go func() {
-		if err := certWatcher.Start(stop); err != nil {
-			log.Error(err, "certificate watcher error")
+	if s.CertsReady != nil {
+		baseHookLog.Info("waiting for the serving certificate to be provisioned")
+		select {
+		case <-s.CertsReady:
+		case <-stop:
+			return nil
 		}
-	}()
-
-	cfg := &tls.Config{
-		NextProtos:     []string{"h2"},
-		GetCertificate: certWatcher.GetCertificate,
 	}
 
-	// load CA to verify client certificate
-	if s.ClientCAName != "" {
-		certPool := x509.NewCertPool()
-		clientCABytes, err := ioutil.ReadFile(filepath.Join(s.CertDir, s.ClientCAName))
-		if err != nil {
-			return fmt.Errorf("failed to read client CA cert: %v", err)
-		}
-
-		ok := certPool.AppendCertsFromPEM(clientCABytes)
-		if !ok {
-			return fmt.Errorf("failed to append client CA cert to CA pool")
-		}
-
-		cfg.ClientCAs = certPool
-		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	cfg, err := certwatcher.NewTLSConfig(certwatcher.Options{
+		CertDir:      s.CertDir,
+		CertName:     s.CertName,
+		KeyName:      s.KeyName,
+		ClientCAName: s.ClientCAName,
+		TLSOpts:      s.TLSOpts,
+	}, stop)
+	if err != nil {
+		return err
 	}
 
 	listener, err := tls.Listen("tcp", net.JoinHostPort(s.Host, strconv.Itoa(int(s.Port))), cfg)
@@ -209,6 +211,10 @@ func (s *Server) Start(stop <-chan struct{}) error {
 
 	log.Info("serving webhook server", "host", s.Host, "port", s.Port)
 
+	s.mu.Lock()
+	s.started = true
+	s.mu.Unlock()
+
 	srv := &http.Server{
 		Handler: s.WebhookMux,
 	}
@@ -235,6 +241,41 @@ func (s *Server) Start(stop <-chan struct{}) error {
 	return nil
 }
 
+// StartedChecker returns a healthz.Checker which checks that the server
+// accepts TLS connections, which makes it suitable as a readiness check.
+// When the server requires client certificates, it only checks that the
+// server accepts TCP connections.
+func (s *Server) StartedChecker() healthz.Checker {
+	return func(req *http.Request) error {
+		// don't hold the lock while dialing, which may take a while
+		s.mu.Lock()
+		started, host, port, clientCAName := s.started, s.Host, s.Port, s.ClientCAName
+		s.mu.Unlock()
+
+		if !started {
+			return fmt.Errorf("webhook server has not been started yet")
+		}
+
+		addr := net.JoinHostPort(host, strconv.Itoa(port))
+		dialer := &net.Dialer{Timeout: 10 * time.Second}
+		if clientCAName != "" {
+			conn, err := dialer.Dial("tcp", addr)
+			if err != nil {
+				return fmt.Errorf("webhook server is not reachable: %w", err)
+			}
+			return conn.Close()
+		}
+
+		// we only want to know whether the server works, not whether its
+		// certificate is trusted
+		conn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{InsecureSkipVerify: true}) // nolint:gosec
+		if err != nil {
+			return fmt.Errorf("webhook server is not reachable: %w", err)
+		}
+		return conn.Close()
+	}
+}
+
 // InjectFunc injects the field setter into the server.
 func (s *Server) InjectFunc(f inject.Func) error {
 	s.setFields = f
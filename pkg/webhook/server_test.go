@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Webhook Server", func() {
+	var stop chan struct{}
+	var server *Server
+
+	BeforeEach(func() {
+		stop = make(chan struct{})
+		server = &Server{
+			Host:    testenv.WebhookInstallOptions.LocalServingHost,
+			Port:    testenv.WebhookInstallOptions.LocalServingPort,
+			CertDir: testenv.WebhookInstallOptions.LocalServingCertDir,
+		}
+	})
+
+	AfterEach(func() {
+		close(stop)
+	})
+
+	Describe("StartedChecker", func() {
+		It("should fail before the server has been started", func() {
+			Expect(server.StartedChecker()(&http.Request{})).NotTo(Succeed())
+		})
+
+		It("should succeed once the server serves connections", func() {
+			go func() {
+				defer GinkgoRecover()
+				Expect(server.Start(stop)).To(Succeed())
+			}()
+
+			Eventually(func() error {
+				return server.StartedChecker()(&http.Request{})
+			}).Should(Succeed())
+		})
+	})
+})
@@ -0,0 +1,188 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// setup-envtest manages the control plane binaries used by envtest.
+//
+// Usage:
+//
+//	setup-envtest [flags] use [VERSION]
+//	    print the directory of the newest installed version matching VERSION
+//	    (e.g. 1.19 or 1.19.2, defaults to the newest version), downloading it
+//	    first if it isn't installed.
+//	setup-envtest [flags] list
+//	    list the installed versions.
+//	setup-envtest [flags] install VERSION ARCHIVE
+//	    install a complete VERSION from a local kubebuilder-tools archive.
+//	setup-envtest [flags] remove VERSION
+//	    remove an installed VERSION.
+//
+// The directory printed by use can be passed to tests through the
+// KUBEBUILDER_ASSETS environment variable, e.g.
+//
+//	export KUBEBUILDER_ASSETS=$(setup-envtest use 1.19)
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	flag "github.com/spf13/pflag"
+
+	"sigs.k8s.io/controller-runtime/pkg/envtest/setup"
+)
+
+var (
+	binDir        = flag.String("bin-dir", "", "directory the binaries are stored in (defaults to a directory in the user cache directory)")
+	goos          = flag.String("os", setup.CurrentPlatform().OS, "operating system to use binaries for")
+	goarch        = flag.String("arch", setup.CurrentPlatform().Arch, "architecture to use binaries for")
+	installedOnly = flag.BoolP("installed-only", "i", false, "only use installed versions, never download (use)")
+	printFormat   = flag.StringP("print", "p", "path", "what to print: path, or env for a shell export statement (use)")
+	sha256Sum     = flag.String("sha256", "", "expected SHA256 checksum of the archive (install)")
+	md5Sum        = flag.String("md5", "", "expected MD5 checksum of the archive (install)")
+	remoteBucket  = flag.String("remote-bucket", "", "bucket to download archives from (defaults to kubebuilder-tools)")
+	remoteServer  = flag.String("remote-server", "", "storage server to download archives from (defaults to https://storage.googleapis.com)")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] use [VERSION] | list | install VERSION ARCHIVE | remove VERSION\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		flag.Usage()
+		return fmt.Errorf("no command given")
+	}
+
+	opts := setup.Options{
+		Platform:      setup.Platform{OS: *goos, Arch: *goarch},
+		InstalledOnly: *installedOnly,
+		Remote:        &setup.Remote{Server: *remoteServer, Bucket: *remoteBucket},
+	}
+	if *binDir != "" {
+		opts.Store = &setup.Store{Root: *binDir}
+	} else {
+		root, err := setup.DefaultStoreRoot()
+		if err != nil {
+			return err
+		}
+		opts.Store = &setup.Store{Root: root}
+	}
+
+	switch cmd, cmdArgs := args[0], args[1:]; cmd {
+	case "use":
+		return use(cmdArgs, opts)
+	case "list":
+		return list(cmdArgs, opts)
+	case "install":
+		return install(cmdArgs, opts)
+	case "remove":
+		return remove(cmdArgs, opts)
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+func use(args []string, opts setup.Options) error {
+	if len(args) > 1 {
+		return fmt.Errorf("use takes at most one version")
+	}
+	selStr := ""
+	if len(args) == 1 {
+		selStr = args[0]
+	}
+	sel, err := setup.ParseSelector(selStr)
+	if err != nil {
+		return err
+	}
+
+	_, dir, err := setup.Use(context.Background(), sel, opts)
+	if err != nil {
+		return err
+	}
+	switch *printFormat {
+	case "path":
+		fmt.Println(dir)
+	case "env":
+		fmt.Printf("export KUBEBUILDER_ASSETS=%q\n", dir)
+	default:
+		return fmt.Errorf("unknown print format %q", *printFormat)
+	}
+	return nil
+}
+
+func list(args []string, opts setup.Options) error {
+	if len(args) != 0 {
+		return fmt.Errorf("list takes no arguments")
+	}
+	versions, err := opts.Store.List(opts.Platform)
+	if err != nil {
+		return err
+	}
+	for _, v := range versions {
+		fmt.Printf("%s\t%s\n", v, opts.Store.Path(v, opts.Platform))
+	}
+	return nil
+}
+
+func install(args []string, opts setup.Options) error {
+	if len(args) != 2 {
+		return fmt.Errorf("install takes a version and an archive")
+	}
+	v, err := setup.ParseVersion(args[0])
+	if err != nil {
+		return err
+	}
+
+	var sum setup.Checksum
+	switch {
+	case *sha256Sum != "" && *md5Sum != "":
+		return fmt.Errorf("only one of --sha256 and --md5 may be given")
+	case *sha256Sum != "":
+		sum = setup.Checksum{Algorithm: "sha256", Value: *sha256Sum}
+	case *md5Sum != "":
+		sum = setup.Checksum{Algorithm: "md5", Value: *md5Sum}
+	default:
+		fmt.Fprintln(os.Stderr, "warning: no checksum given, the archive will not be verified")
+	}
+
+	dir, err := setup.InstallFromFile(v, args[1], sum, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Println(dir)
+	return nil
+}
+
+func remove(args []string, opts setup.Options) error {
+	if len(args) != 1 {
+		return fmt.Errorf("remove takes a version")
+	}
+	v, err := setup.ParseVersion(args[0])
+	if err != nil {
+		return err
+	}
+	return opts.Store.Remove(v, opts.Platform)
+}